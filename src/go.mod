@@ -3,13 +3,16 @@ module github.com/zokwezo/sango/src
 go 1.22.2
 
 require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
 	github.com/lib/pq v1.10.9
 	github.com/rivo/uniseg v0.4.7
 	github.com/spf13/cobra v1.8.1
+	golang.org/x/term v0.24.0
 	golang.org/x/text v0.18.0
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	golang.org/x/sys v0.25.0 // indirect
 )
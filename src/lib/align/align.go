@@ -0,0 +1,284 @@
+// Aligns the words of Sango sentences with the words of their translations.
+//
+// This is an IBM Model 1 word aligner trained with expectation maximization,
+// optionally followed by IBM Model 2 iterations that learn a distortion
+// distribution over relative word positions (bucketed by distance from the
+// diagonal). Each target word is aligned to at most one Sango word (or none).
+// Lexicon English translations act as a Dirichlet prior, adding pseudo-counts
+// to the (Sango, English) word pairs they attest in every maximization step.
+
+package align
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/zokwezo/sango/src/lib/corpus"
+	"github.com/zokwezo/sango/src/lib/lexicon"
+	"golang.org/x/text/unicode/norm"
+)
+
+// A parallel sentence pair, tokenized into comparable word keys.
+type Pair struct {
+	Source []string // Sango words (see lexicon.TonelessKey)
+	Target []string // translation words (lowercase)
+}
+
+// A link between Source[Source] and Target[Target] (both 0-based).
+type Link struct {
+	Source int
+	Target int
+}
+
+// Maps a Sango word key to the translation words it is a priori likely to align with.
+type Prior = map[string]map[string]bool
+
+type Options struct {
+	Model1Iterations int
+	Model2Iterations int
+	PriorWeight      float64 // pseudo-count added for each prior pair
+}
+
+var DefaultOptions = Options{Model1Iterations: 10, Model2Iterations: 5, PriorWeight: 1}
+
+type Model struct {
+	translation map[string]map[string]float64 // t(target | source)
+	distortion  map[int]float64               // a(bucket), nil for Model 1
+}
+
+func Train(pairs []Pair, prior Prior, options Options) *Model {
+	return train(pairs, prior, options)
+}
+
+// Returns the most probable link for each target word not best aligned to NULL.
+func (m *Model) Align(pair Pair) []Link {
+	return m.align(pair)
+}
+
+// Probability that source word s translates as target word e.
+func (m *Model) Probability(e, s string) float64 {
+	return m.translation[s][e]
+}
+
+// Derives an English prior from the lexicon EnglishTranslation column.
+func LexiconPrior() Prior {
+	prior := Prior{}
+	for _, row := range lexicon.LexiconRows() {
+		if row.Toneless == "" {
+			continue
+		}
+		key := row.TonelessKey()
+		for _, e := range TargetWords(strings.ReplaceAll(row.EnglishTranslation, "-", " ")) {
+			if prior[key] == nil {
+				prior[key] = map[string]bool{}
+			}
+			prior[key][e] = true
+		}
+	}
+	return prior
+}
+
+// Splits a translation into lowercase words, dropping punctuation.
+func TargetWords(text string) []string {
+	var words []string
+	for _, w := range targetWordRE.FindAllString(norm.NFC.String(text), -1) {
+		words = append(words, strings.ToLower(w))
+	}
+	return words
+}
+
+// Builds the sentence pair for the text_<lang> translation of a CoNLL-U sentence.
+// Also returns, for each Source index, the index of that word within sentence.Words().
+func PairFromSentence(sentence corpus.Sentence, lang string) (Pair, []int) {
+	var pair Pair
+	var wordIndex []int
+	for k, word := range sentence.Words() {
+		key := lexicon.TonelessKey(word.Form)
+		if word.UPOS == "PUNCT" || key == "" {
+			continue
+		}
+		pair.Source = append(pair.Source, key)
+		wordIndex = append(wordIndex, k)
+	}
+	pair.Target = TargetWords(sentence.Comment("text_" + lang))
+	return pair, wordIndex
+}
+
+// Writes one line per sentence of 0-based "source-target" pairs, where source
+// indexes sentence.Words() (including punctuation) and target indexes TargetWords.
+func WritePharaoh(out io.Writer, links [][]Link, wordIndexes [][]int) error {
+	w := bufio.NewWriter(out)
+	for k, sentenceLinks := range links {
+		var pairs []string
+		for _, link := range sentenceLinks {
+			pairs = append(pairs, fmt.Sprintf("%v-%v", wordIndexes[k][link.Source], link.Target))
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(pairs, " ")); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Sets MISC Align_<lang>=j1,j2,... (1-based target word positions) on each aligned word.
+func Annotate(sentence *corpus.Sentence, lang string, links []Link, wordIndex []int) {
+	targets := map[int][]string{}
+	for _, link := range links {
+		k := wordIndex[link.Source]
+		targets[k] = append(targets[k], fmt.Sprint(link.Target+1))
+	}
+	k := -1
+	for n := range sentence.Tokens {
+		token := &sentence.Tokens[n]
+		if token.IsMultiword() || token.IsEmptyNode() {
+			continue
+		}
+		k++
+		if t, found := targets[k]; found {
+			token.SetMisc("Align_"+lang, strings.Join(t, ","))
+		}
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+var targetWordRE = regexp.MustCompile(`[\p{L}\p{M}\p{N}]+`)
+
+const null = "" // the empty source word, to which unaligned target words align
+
+// Distances from the diagonal are bucketed from -numBuckets to numBuckets,
+// and alignments to the NULL word are counted apart in nullBucket.
+const (
+	numBuckets = 10
+	nullBucket = 2*numBuckets + 1
+)
+
+// Buckets the distance from the diagonal of source position i (of l) and target position j (of m).
+func bucket(i, l, j, m int) int {
+	return int(math.Round(numBuckets * (float64(i+1)/float64(l) - float64(j+1)/float64(m))))
+}
+
+func (m *Model) distortionOf(i, l, j, n int) float64 {
+	if m.distortion == nil {
+		return 1
+	}
+	if i < 0 {
+		return m.distortion[nullBucket]
+	}
+	return m.distortion[bucket(i, l, j, n)]
+}
+
+func train(pairs []Pair, prior Prior, options Options) *Model {
+	m := &Model{translation: map[string]map[string]float64{}}
+
+	// Initialize t(e|s) uniformly over co-occurring words.
+	targetVocabulary := map[string]bool{}
+	for _, p := range pairs {
+		for _, e := range p.Target {
+			targetVocabulary[e] = true
+		}
+	}
+	uniform := 1 / float64(max(1, len(targetVocabulary)))
+	for _, p := range pairs {
+		for _, s := range append([]string{null}, p.Source...) {
+			if m.translation[s] == nil {
+				m.translation[s] = map[string]float64{}
+			}
+			for _, e := range p.Target {
+				m.translation[s][e] = uniform
+			}
+		}
+	}
+
+	iterate := func() {
+		count := map[string]map[string]float64{}
+		distortionCount := map[int]float64{}
+		for _, p := range pairs {
+			l, n := len(p.Source), len(p.Target)
+			for j, e := range p.Target {
+				denominator := m.translation[null][e] * m.distortionOf(-1, l, j, n)
+				for i, s := range p.Source {
+					denominator += m.translation[s][e] * m.distortionOf(i, l, j, n)
+				}
+				if denominator == 0 {
+					continue
+				}
+				for i := -1; i < l; i++ {
+					s := null
+					b := nullBucket
+					if i >= 0 {
+						s = p.Source[i]
+						b = bucket(i, l, j, n)
+					}
+					c := m.translation[s][e] * m.distortionOf(i, l, j, n) / denominator
+					if count[s] == nil {
+						count[s] = map[string]float64{}
+					}
+					count[s][e] += c
+					distortionCount[b] += c
+				}
+			}
+		}
+		for s, counts := range count {
+			for e := range prior[s] {
+				if _, found := counts[e]; found {
+					counts[e] += options.PriorWeight
+				}
+			}
+			total := 0.0
+			for _, c := range counts {
+				total += c
+			}
+			for e, c := range counts {
+				m.translation[s][e] = c / total
+			}
+		}
+		if m.distortion != nil && len(distortionCount) > 0 {
+			total := 0.0
+			for _, c := range distortionCount {
+				total += c
+			}
+			for b := range m.distortion {
+				m.distortion[b] = distortionCount[b] / total
+			}
+		}
+	}
+
+	for range options.Model1Iterations {
+		iterate()
+	}
+	if options.Model2Iterations > 0 {
+		uniform := 1 / float64(2*numBuckets+2)
+		m.distortion = map[int]float64{nullBucket: uniform}
+		for b := -numBuckets; b <= numBuckets; b++ {
+			m.distortion[b] = uniform
+		}
+		for range options.Model2Iterations {
+			iterate()
+		}
+	}
+	return m
+}
+
+func (m *Model) align(pair Pair) []Link {
+	var links []Link
+	l, n := len(pair.Source), len(pair.Target)
+	for j, e := range pair.Target {
+		best := -1
+		bestP := m.translation[null][e] * m.distortionOf(-1, l, j, n)
+		for i, s := range pair.Source {
+			if p := m.translation[s][e] * m.distortionOf(i, l, j, n); p > bestP {
+				best, bestP = i, p
+			}
+		}
+		if best >= 0 {
+			links = append(links, Link{Source: best, Target: j})
+		}
+	}
+	return links
+}
@@ -0,0 +1,103 @@
+package align
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zokwezo/sango/src/lib/corpus"
+	"github.com/zokwezo/sango/src/lib/lexicon"
+)
+
+func pair(source, target string) Pair {
+	var p Pair
+	for _, w := range strings.Fields(source) {
+		p.Source = append(p.Source, lexicon.TonelessKey(w))
+	}
+	p.Target = TargetWords(target)
+	return p
+}
+
+var pairs = []Pair{
+	pair("kɔ̂bɛ", "food"),
+	pair("lo te kɔ̂bɛ", "he eats food"),
+	pair("lo te kɔ̂bɛ", "she eats food"),
+	pair("lo bâa ngûru", "he sees pig"),
+	pair("ngûru ate kɔ̂bɛ", "pig eats food"),
+	pair("lo bâa kɔ̂bɛ", "she sees food"),
+}
+
+func TestTrainModel1(t *testing.T) {
+	m := Train(pairs, nil, Options{Model1Iterations: 20})
+	links := m.Align(pair("ngûru bâa kɔ̂bɛ", "pig sees food"))
+	expect := []Link{{0, 0}, {1, 1}, {2, 2}}
+	if len(links) != len(expect) {
+		t.Fatalf("\nexpect: %v\nactual: %v", expect, links)
+	}
+	for k := range expect {
+		if links[k] != expect[k] {
+			t.Errorf("\nexpect: %v\nactual: %v", expect, links)
+		}
+	}
+}
+
+func TestTrainModel2Distortion(t *testing.T) {
+	m := Train(pairs, nil, Options{Model1Iterations: 5, Model2Iterations: 5})
+	if len(m.distortion) != 2*numBuckets+2 {
+		t.Errorf("%v distortion buckets, expected %v", len(m.distortion), 2*numBuckets+2)
+	}
+	total := 0.0
+	for b, p := range m.distortion {
+		if (b < -numBuckets || b > numBuckets) && b != nullBucket {
+			t.Errorf("unexpected distortion bucket %v", b)
+		}
+		total += p
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("distortion sums to %v", total)
+	}
+	if links := m.Align(pair("ngûru bâa kɔ̂bɛ", "pig sees food")); len(links) != 3 || links[2] != (Link{2, 2}) {
+		t.Errorf("links = %v", links)
+	}
+}
+
+func TestLexiconPriorBreaksTies(t *testing.T) {
+	// Without a prior, two words that always co-occur are indistinguishable.
+	tied := []Pair{pair("kɔ̂bɛ ngûru", "food pig"), pair("kɔ̂bɛ ngûru", "pig food")}
+	m := Train(tied, LexiconPrior(), Options{Model1Iterations: 5, Model2Iterations: 0, PriorWeight: 1})
+	kobe := lexicon.TonelessKey("kobe")
+	if m.Probability("food", kobe) <= m.Probability("pig", kobe) {
+		t.Errorf("t(food|kobe) = %v <= t(pig|kobe) = %v",
+			m.Probability("food", kobe), m.Probability("pig", kobe))
+	}
+}
+
+func TestAnnotateAndWritePharaoh(t *testing.T) {
+	sentences, err := corpus.Parse(strings.NewReader(
+		"# text_en = Spider and Pig\n" +
+			"1\tTɛrɛ\tTɛrɛ\tPROPN\t_\t_\t_\t_\t_\tGloss=spider\n" +
+			"2\t,\t_\tPUNCT\t_\t_\t_\t_\t_\t_\n" +
+			"3\tngûru\tngûru\tNOUN\t_\t_\t_\t_\t_\tGloss=pig\n\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, wordIndex := PairFromSentence(sentences[0], "en")
+	source := []string{lexicon.TonelessKey("tere"), lexicon.TonelessKey("nguru")}
+	if !slices.Equal(p.Source, source) || strings.Join(p.Target, " ") != "spider and pig" {
+		t.Fatalf("bad pair %v", p)
+	}
+	links := []Link{{0, 0}, {1, 2}}
+	var out bytes.Buffer
+	if err := WritePharaoh(&out, [][]Link{links}, [][]int{wordIndex}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "0-0 2-2\n" {
+		t.Errorf("Pharaoh = %q", out.String())
+	}
+	Annotate(&sentences[0], "en", links, wordIndex)
+	if misc := sentences[0].Tokens[2].Misc; misc != "Gloss=pig|Align_en=3" {
+		t.Errorf("Misc = %q", misc)
+	}
+}
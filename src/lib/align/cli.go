@@ -0,0 +1,72 @@
+package align
+
+import (
+	"bufio"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zokwezo/sango/src/lib/corpus"
+)
+
+func Init(rootCmd *cobra.Command) {
+	alignCmd.Flags().StringVar(&langFlagValue, "lang", "en", "Language of the text_xx translation to align with: en, fr, or de.")
+	alignCmd.Flags().StringVar(&formatFlagValue, "format", "pharaoh", "Output format: pharaoh or conllu (MISC Align_xx=).")
+	alignCmd.Flags().IntVar(&model1IterationsFlagValue, "model1_iterations", DefaultOptions.Model1Iterations, "Number of IBM Model 1 EM iterations.")
+	alignCmd.Flags().IntVar(&model2IterationsFlagValue, "model2_iterations", DefaultOptions.Model2Iterations, "Number of IBM Model 2 EM iterations (0 for Model 1 only).")
+	alignCmd.Flags().Float64Var(&priorWeightFlagValue, "prior_weight", DefaultOptions.PriorWeight, "Pseudo-count for each lexicon gloss (English only, 0 to disable).")
+	rootCmd.AddCommand(alignCmd)
+}
+
+var (
+	langFlagValue             string
+	formatFlagValue           string
+	model1IterationsFlagValue int
+	model2IterationsFlagValue int
+	priorWeightFlagValue      float64
+
+	alignCmd = &cobra.Command{
+		Use:   "align [file.conllu...]",
+		Short: "Read CoNLL-U from files (or stdin), train a word aligner on its translations, then write alignments to stdout",
+		Run: func(cmd *cobra.Command, args []string) {
+			sentences, err := corpus.ReadFiles(args)
+			if err != nil {
+				log.Fatal(err)
+			}
+			pairs := make([]Pair, len(sentences))
+			wordIndexes := make([][]int, len(sentences))
+			for k, s := range sentences {
+				pairs[k], wordIndexes[k] = PairFromSentence(s, langFlagValue)
+			}
+			var prior Prior
+			if langFlagValue == "en" {
+				prior = LexiconPrior()
+			}
+			model := Train(pairs, prior, Options{
+				Model1Iterations: model1IterationsFlagValue,
+				Model2Iterations: model2IterationsFlagValue,
+				PriorWeight:      priorWeightFlagValue,
+			})
+			links := make([][]Link, len(pairs))
+			for k, pair := range pairs {
+				links[k] = model.Align(pair)
+			}
+			out := bufio.NewWriter(os.Stdout)
+			defer out.Flush()
+			switch formatFlagValue {
+			case "pharaoh":
+				err = WritePharaoh(out, links, wordIndexes)
+			case "conllu":
+				for k := range sentences {
+					Annotate(&sentences[k], langFlagValue, links[k], wordIndexes[k])
+				}
+				err = corpus.Write(out, sentences)
+			default:
+				log.Fatalf("Unknown --format %q", formatFlagValue)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+)
@@ -0,0 +1,117 @@
+package corpus
+
+import (
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zokwezo/sango/src/lib/lexicon"
+)
+
+func Init(rootCmd *cobra.Command) {
+	kwicCmd.Flags().StringVar(&formFlagValue, "form", "", "Returns words where this regexp partially matches the surface form.")
+	kwicCmd.Flags().StringVar(&tonelessFlagValue, "toneless", "", "Returns words with this toneless form (written in any spelling, tones ignored).")
+	kwicCmd.Flags().StringVar(&lemmaFlagValue, "lemma", "", "Returns words where this regexp partially matches the lemma.")
+	kwicCmd.Flags().StringVar(&uposFlagValue, "upos", "", "Returns words where this regexp partially matches the UPOS.")
+	kwicCmd.Flags().StringVar(&featsFlagValue, "feats", "", "Returns words where this regexp partially matches the FEATS.")
+	kwicCmd.Flags().StringVar(&toneFlagValue, "tone", "", "Returns words where this regexp partially matches the tone melody, e.g. ^HL$ (H=high M=mid L=low U=unknown).")
+	kwicCmd.Flags().StringVar(&sortFlagValue, "sort", "", "Sort hits by left or right context (default: corpus order).")
+	kwicCmd.Flags().IntVar(&contextFlagValue, "context", 8, "Maximum number of context words on each side.")
+	kwicCmd.Flags().IntVar(&widthFlagValue, "width", 40, "Maximum display width of the context on each side.")
+	kwicCmd.Flags().StringVar(&translationsFlagValue, "translations", "en,fr", "Comma-separated languages of the text_xx translations to show.")
+	corpusCmd.AddCommand(kwicCmd)
+	statsCmd.Flags().IntVar(&limitFlagValue, "limit", 50, "Maximum number of entries listed per table (0 for all).")
+	statsCmd.Flags().StringVar(&frequencyFlagValue, "frequency", "", "Instead of the report, validate (list rows whose lexicon Frequency differs from the corpus) or recompute (write the lexicon as CSV).")
+	corpusCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(corpusCmd)
+}
+
+var (
+	formFlagValue         string
+	tonelessFlagValue     string
+	lemmaFlagValue        string
+	uposFlagValue         string
+	featsFlagValue        string
+	toneFlagValue         string
+	sortFlagValue         string
+	contextFlagValue      int
+	widthFlagValue        int
+	translationsFlagValue string
+	limitFlagValue        int
+	frequencyFlagValue    string
+
+	corpusCmd = &cobra.Command{
+		Use:   "corpus",
+		Short: "A CLI to search and summarize Sango corpora in CoNLL-U format",
+	}
+
+	kwicCmd = &cobra.Command{
+		Use:   "kwic [file.conllu...]",
+		Short: "Read CoNLL-U from files (or stdin), search for matching words, then write keyword-in-context lines to stdout",
+		Run: func(cmd *cobra.Command, args []string) {
+			query := Query{
+				FormRE:   compileFlag(formFlagValue),
+				LemmaRE:  compileFlag(lemmaFlagValue),
+				UPOSRE:   compileFlag(uposFlagValue),
+				FeatsRE:  compileFlag(featsFlagValue),
+				ToneRE:   compileFlag(toneFlagValue),
+				Toneless: tonelessFlagValue,
+			}
+			if query == (Query{}) {
+				log.Fatal("Expected at least one of --form, --toneless, --lemma, --upos, --feats, or --tone")
+			}
+			sentences, err := ReadFiles(args)
+			if err != nil {
+				log.Fatal(err)
+			}
+			hits := Search(sentences, query, contextFlagValue)
+			if err := SortHits(hits, sortFlagValue); err != nil {
+				log.Fatal(err)
+			}
+			var langs []string
+			for _, lang := range strings.Split(translationsFlagValue, ",") {
+				if lang = strings.TrimSpace(lang); lang != "" {
+					langs = append(langs, lang)
+				}
+			}
+			if err := WriteKWIC(os.Stdout, hits, widthFlagValue, langs); err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+
+	statsCmd = &cobra.Command{
+		Use:   "stats [file.conllu...]",
+		Short: "Read CoNLL-U from files (or stdin), count tokens, types, UPOS, lemmas, and lexicon coverage, then write a report to stdout",
+		Run: func(cmd *cobra.Command, args []string) {
+			sentences, err := ReadFiles(args)
+			if err != nil {
+				log.Fatal(err)
+			}
+			rows := lexicon.LexiconRows()
+			stats := ComputeStats(sentences, rows)
+			switch frequencyFlagValue {
+			case "":
+				err = WriteStats(os.Stdout, stats, limitFlagValue)
+			case "validate":
+				err = WriteFrequencyValidation(os.Stdout, stats)
+			case "recompute":
+				err = WriteRecomputedFrequencies(os.Stdout, stats, rows)
+			default:
+				log.Fatalf("Unknown --frequency %q", frequencyFlagValue)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+)
+
+func compileFlag(flagValue string) *regexp.Regexp {
+	if flagValue == "" {
+		return nil
+	}
+	return regexp.MustCompile(flagValue)
+}
@@ -0,0 +1,248 @@
+// Reads and writes annotated Sango corpora in CoNLL-U format.
+//
+// See https://universaldependencies.org/format.html for the file format.
+// Sentence-level comments (e.g. "# text_en = ...") are kept in order,
+// and an underscore in any token field is read as (and written from) "".
+
+package corpus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type Comment struct {
+	Key   string // "" if the comment is not of the form "# key = value"
+	Value string
+}
+
+type Token struct {
+	ID     string // "1", or "1-2" for a multiword token, or "1.1" for an empty node
+	Form   string
+	Lemma  string
+	UPOS   string
+	XPOS   string
+	Feats  string
+	Head   string
+	Deprel string
+	Deps   string
+	Misc   string
+}
+
+type Sentence struct {
+	Comments []Comment
+	Tokens   []Token
+}
+
+// Returns the value of the first comment with the given key, else "".
+func (s *Sentence) Comment(key string) string {
+	for _, c := range s.Comments {
+		if c.Key == key {
+			return c.Value
+		}
+	}
+	return ""
+}
+
+// Replaces the value of the first comment with the given key, else appends one.
+func (s *Sentence) SetComment(key, value string) {
+	for k, c := range s.Comments {
+		if c.Key == key {
+			s.Comments[k].Value = value
+			return
+		}
+	}
+	s.Comments = append(s.Comments, Comment{Key: key, Value: value})
+}
+
+// Returns only the syntactic words, skipping multiword tokens and empty nodes.
+func (s *Sentence) Words() []Token {
+	var words []Token
+	for _, t := range s.Tokens {
+		if !t.IsMultiword() && !t.IsEmptyNode() {
+			words = append(words, t)
+		}
+	}
+	return words
+}
+
+func (t *Token) IsMultiword() bool { return strings.Contains(t.ID, "-") }
+func (t *Token) IsEmptyNode() bool { return strings.Contains(t.ID, ".") }
+
+// Returns the value of the MISC attribute with the given key (e.g. "Gloss"), else "".
+func (t *Token) MiscValue(key string) string {
+	return attributeValue(t.Misc, key)
+}
+
+// Replaces the value of the MISC attribute with the given key, else appends one.
+func (t *Token) SetMisc(key, value string) {
+	t.Misc = setAttributeValue(t.Misc, key, value)
+}
+
+// Returns the value of the FEATS attribute with the given key (e.g. "Num"), else "".
+func (t *Token) FeatValue(key string) string {
+	return attributeValue(t.Feats, key)
+}
+
+func Parse(in io.Reader) ([]Sentence, error) {
+	return parse(in)
+}
+
+func Write(out io.Writer, sentences []Sentence) error {
+	return write(out, sentences)
+}
+
+// Parses and concatenates the named CoNLL-U files, or stdin if there are none.
+func ReadFiles(paths []string) ([]Sentence, error) {
+	if len(paths) == 0 {
+		return parse(bufio.NewReader(os.Stdin))
+	}
+	var sentences []Sentence
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		s, err := parse(bufio.NewReader(f))
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		sentences = append(sentences, s...)
+	}
+	return sentences, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+func parse(in io.Reader) ([]Sentence, error) {
+	var sentences []Sentence
+	var sentence Sentence
+	flush := func() {
+		if len(sentence.Comments) > 0 || len(sentence.Tokens) > 0 {
+			sentences = append(sentences, sentence)
+		}
+		sentence = Sentence{}
+	}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case strings.HasPrefix(line, "#"):
+			sentence.Comments = append(sentence.Comments, parseComment(line))
+		default:
+			fields := strings.Split(line, "\t")
+			if len(fields) != 10 {
+				return sentences, fmt.Errorf("line %v: expected 10 tab-separated fields, found %v", n, len(fields))
+			}
+			for k, field := range fields {
+				if field == "_" {
+					fields[k] = ""
+				}
+			}
+			if fields[0] == "" {
+				return sentences, fmt.Errorf("line %v: missing token ID", n)
+			}
+			sentence.Tokens = append(sentence.Tokens, Token{
+				ID:     fields[0],
+				Form:   fields[1],
+				Lemma:  fields[2],
+				UPOS:   fields[3],
+				XPOS:   fields[4],
+				Feats:  fields[5],
+				Head:   fields[6],
+				Deprel: fields[7],
+				Deps:   fields[8],
+				Misc:   fields[9],
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return sentences, err
+	}
+	flush()
+	return sentences, nil
+}
+
+func parseComment(line string) Comment {
+	body := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+	if key, value, found := strings.Cut(body, "="); found {
+		return Comment{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)}
+	}
+	return Comment{Value: body}
+}
+
+func write(out io.Writer, sentences []Sentence) error {
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+	orUnderscore := func(s string) string {
+		if s == "" {
+			return "_"
+		}
+		return s
+	}
+	for _, sentence := range sentences {
+		for _, c := range sentence.Comments {
+			var err error
+			if c.Key == "" {
+				_, err = fmt.Fprintf(w, "# %s\n", c.Value)
+			} else {
+				_, err = fmt.Fprintf(w, "# %s = %s\n", c.Key, c.Value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		for _, t := range sentence.Tokens {
+			fields := []string{t.ID, t.Form, t.Lemma, t.UPOS, t.XPOS, t.Feats, t.Head, t.Deprel, t.Deps, t.Misc}
+			for k, field := range fields {
+				fields[k] = orUnderscore(field)
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func attributeValue(attributes, key string) string {
+	for _, a := range strings.Split(attributes, "|") {
+		if k, v, found := strings.Cut(a, "="); found && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+func setAttributeValue(attributes, key, value string) string {
+	var out []string
+	found := false
+	for _, a := range strings.Split(attributes, "|") {
+		if a == "" {
+			continue
+		}
+		if k, _, _ := strings.Cut(a, "="); k == key {
+			if !found {
+				out = append(out, key+"="+value)
+				found = true
+			}
+			continue
+		}
+		out = append(out, a)
+	}
+	if !found {
+		out = append(out, key+"="+value)
+	}
+	return strings.Join(out, "|")
+}
@@ -0,0 +1,159 @@
+package corpus
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/zokwezo/sango/src/lib/lexicon"
+)
+
+const tereNaNguru = `# sent id = tere_na_nguru-p1s1
+# text = Tɛrɛ na Ngûru
+# text_en = Spider and Pig
+1	Tɛrɛ	Tɛrɛ	PROPN	_	_	_	_	_	Gloss=spider
+2	na	na	ADP	_	_	_	_	_	Gloss=and
+3	Ngûru	Ngûru	PROPN	_	_	_	_	_	Gloss=pig
+
+`
+
+func TestParseAndWriteRoundTrip(t *testing.T) {
+	sentences, err := Parse(strings.NewReader(tereNaNguru))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sentences) != 1 {
+		t.Fatalf("len(sentences) = %v, expected 1", len(sentences))
+	}
+	s := sentences[0]
+	if actual := s.Comment("text_en"); actual != "Spider and Pig" {
+		t.Errorf("Comment(text_en) = %q", actual)
+	}
+	if len(s.Tokens) != 3 || s.Tokens[2].Form != "Ngûru" || s.Tokens[2].XPOS != "" {
+		t.Errorf("Tokens = %v", s.Tokens)
+	}
+	if actual := s.Tokens[0].MiscValue("Gloss"); actual != "spider" {
+		t.Errorf("MiscValue(Gloss) = %q", actual)
+	}
+	var out bytes.Buffer
+	if err := Write(&out, sentences); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != tereNaNguru {
+		t.Errorf("actual:\n%s\nexpect:\n%s", out.String(), tereNaNguru)
+	}
+}
+
+func TestParseBadFieldCount(t *testing.T) {
+	if _, err := Parse(strings.NewReader("1\tTɛrɛ\tTɛrɛ\n")); err == nil {
+		t.Error("expected an error for a line with 3 fields")
+	}
+}
+
+func TestSetMisc(t *testing.T) {
+	tok := Token{Misc: "SpaceAfter=No|Gloss=old"}
+	tok.SetMisc("Gloss", "new")
+	tok.SetMisc("Align", "2")
+	if tok.Misc != "SpaceAfter=No|Gloss=new|Align=2" {
+		t.Errorf("Misc = %q", tok.Misc)
+	}
+}
+
+const kwicCorpus = tereNaNguru + `# sent id = tere_na_nguru-p2s1
+# text = Tɛrɛ ahûnda kɔ̂bɛ na Ngûru.
+# text_en = Spider asks Pig for food.
+1	Tɛrɛ	Tɛrɛ	PROPN	_	_	_	_	_	_
+2	a	a	PRON	_	Person=3|Prefix=Yes	_	_	_	_
+3	hûnda	hûnda	VERB	_	Subcat=Tran	_	_	_	_
+4	kɔ̂bɛ	kɔ̂bɛ	NOUN	_	_	_	_	_	_
+5	na	na	ADP	_	_	_	_	_	_
+6	Ngûru	Ngûru	PROPN	_	_	_	_	_	_
+7	.	.	PUNCT	_	_	_	_	_	_
+
+`
+
+func TestToneMelody(t *testing.T) {
+	if actual := ToneMelody("Ngûru ahön-ndönî"); actual != "HLLMMH" {
+		t.Errorf("ToneMelody = %q", actual)
+	}
+}
+
+func TestSearchAndWriteKWIC(t *testing.T) {
+	sentences, err := Parse(strings.NewReader(kwicCorpus))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits := Search(sentences, Query{Toneless: "ngüru"}, 2)
+	if len(hits) != 2 {
+		t.Fatalf("len(hits) = %v, expected 2", len(hits))
+	}
+	if err := SortHits(hits, "right"); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := WriteKWIC(&out, hits, 10, []string{"en"}); err != nil {
+		t.Fatal(err)
+	}
+	expect := `   Tɛrɛ na  Ngûru  
+    en: ‘Spider and Pig’
+   kɔ̂bɛ na  Ngûru  .
+    en: ‘Spider asks Pig for food.’
+`
+	if out.String() != expect {
+		t.Errorf("actual:\n%s\nexpect:\n%s", out.String(), expect)
+	}
+	if hits := Search(sentences, Query{ToneRE: regexp.MustCompile(`^HL$`), UPOSRE: regexp.MustCompile(`NOUN|VERB`)}, 0); len(hits) != 2 {
+		t.Errorf("tone search found %v hits, expected 2", len(hits))
+	}
+	if err := SortHits(hits, "middle"); err == nil {
+		t.Error("expected an error for an unknown sort")
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	sentences, err := Parse(strings.NewReader(kwicCorpus))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := lexicon.Lookup(lexicon.LexiconRows(), lexicon.DictRowRegexp{
+		TonelessRE: regexp.MustCompile(`^(hunda|kobe|na|nguru)$`),
+	})
+	stats := ComputeStats(sentences, rows)
+	if stats.Tokens != 9 || stats.Types != 6 || stats.UnmatchedCount != 3 {
+		t.Errorf("Tokens = %v, Types = %v, UnmatchedCount = %v", stats.Tokens, stats.Types, stats.UnmatchedCount)
+	}
+	if stats.UPOS[0] != (Count{"PROPN", 4}) || stats.Lemmas[0] != (Count{"na", 2}) {
+		t.Errorf("UPOS = %v, Lemmas = %v", stats.UPOS, stats.Lemmas)
+	}
+	if len(stats.Unmatched) != 2 || stats.Unmatched[0] != (Count{"tɛrɛ", 2}) {
+		t.Errorf("Unmatched = %v", stats.Unmatched)
+	}
+	if len(stats.Mismatches) != 1 || stats.Mismatches[0].Lemma != "ngûru" || stats.Mismatches[0].UPOS != "PROPN" {
+		t.Errorf("Mismatches = %v", stats.Mismatches)
+	}
+	if len(stats.Unattested) != 2 || stats.Unattested[0].UDPos != "NOUN" || stats.Unattested[1].UDPos != "CCONJ" {
+		t.Errorf("Unattested = %v", stats.Unattested)
+	}
+	if len(stats.Attested) != 4 || stats.Attested[0].Row.Toneless != "na" || stats.Attested[0].Count != 2 {
+		t.Errorf("Attested = %v", stats.Attested)
+	}
+	var out bytes.Buffer
+	if err := WriteRecomputedFrequencies(&out, stats, rows); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"kobe","kôbe","kɔ̂bɛ","kc^bx_","NOUN","","FOOD",1,"food","food"`) ||
+		!strings.Contains(out.String(), `"nguru","ngûru","ngûru","Gu^ru_","NOUN","","ANIM",1,"pig","pig"`) {
+		t.Errorf("recomputed CSV:\n%s", out.String())
+	}
+}
+
+func TestSuggestedFrequency(t *testing.T) {
+	for _, test := range []struct{ count, numTokens, expect int }{
+		{0, 100, 0}, {1, 100, 1}, {1, 1000, 3}, {1, 100000, 6},
+	} {
+		if actual := SuggestedFrequency(test.count, test.numTokens); actual != test.expect {
+			t.Errorf("SuggestedFrequency(%v, %v) = %v, expected %v", test.count, test.numTokens, actual, test.expect)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Keyword-in-context (KWIC) concordance search over CoNLL-U sentences.
+//
+// Words can be matched by surface form, toneless form, lemma, UPOS, FEATS,
+// or tone melody. Toneless forms are compared by lexicon.TonelessKey and tone
+// melodies are derived from the SSE encoding, so matching is insensitive to case,
+// vowel height, and (for the toneless form) pitch.
+
+package corpus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rivo/uniseg"
+	"github.com/zokwezo/sango/src/lib/lexicon"
+	"github.com/zokwezo/sango/src/lib/sse"
+)
+
+// Each non-nil regexp must partially match the corresponding word field,
+// and a nonempty Toneless must equal the toneless form of the word.
+type Query struct {
+	FormRE   *regexp.Regexp
+	LemmaRE  *regexp.Regexp
+	UPOSRE   *regexp.Regexp
+	FeatsRE  *regexp.Regexp
+	ToneRE   *regexp.Regexp // matched against ToneMelody(word.Form)
+	Toneless string         // any spelling, e.g. "kɔ̂bɛ", "kôbe", or "kobe"
+}
+
+type Hit struct {
+	Sentence *Sentence
+	Word     int // index into Sentence.Words()
+	Left     []string
+	Keyword  string
+	Right    []string
+}
+
+// Returns one letter per Sango syllable: H(igh), M(id), L(ow), or U(nknown), e.g. kɔ̂bɛ -> HL.
+func ToneMelody(s string) string {
+	sses, _ := sse.UTF8ToSSEs(s)
+	var b strings.Builder
+	for _, x := range sses {
+		b.WriteString(x.Melody()) // Unicode runes have no tone
+	}
+	return b.String()
+}
+
+func (q *Query) Matches(word Token) bool {
+	return (q.FormRE == nil || q.FormRE.MatchString(word.Form)) &&
+		(q.LemmaRE == nil || q.LemmaRE.MatchString(word.Lemma)) &&
+		(q.UPOSRE == nil || q.UPOSRE.MatchString(word.UPOS)) &&
+		(q.FeatsRE == nil || q.FeatsRE.MatchString(word.Feats)) &&
+		(q.ToneRE == nil || q.ToneRE.MatchString(ToneMelody(word.Form))) &&
+		(q.Toneless == "" || lexicon.TonelessKey(q.Toneless) == lexicon.TonelessKey(word.Form))
+}
+
+// Returns every word matching the query, with up to numContext words on each side.
+func Search(sentences []Sentence, query Query, numContext int) []Hit {
+	return search(sentences, query, numContext)
+}
+
+// Sorts hits by their left context (nearest word first) or right context, else leaves them in corpus order.
+func SortHits(hits []Hit, by string) error {
+	return sortHits(hits, by)
+}
+
+// Writes one line per hit with the keyword centered between width columns of context,
+// followed by one indented line for each available text_<lang> translation.
+func WriteKWIC(out io.Writer, hits []Hit, width int, langs []string) error {
+	return writeKWIC(out, hits, width, langs)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+func search(sentences []Sentence, query Query, numContext int) []Hit {
+	var hits []Hit
+	for k := range sentences {
+		s := &sentences[k]
+		words := s.Words()
+		for n, word := range words {
+			if !query.Matches(word) {
+				continue
+			}
+			var hit = Hit{Sentence: s, Word: n, Keyword: word.Form}
+			for _, w := range words[max(0, n-numContext):n] {
+				hit.Left = append(hit.Left, w.Form)
+			}
+			for _, w := range words[n+1 : min(len(words), n+1+numContext)] {
+				hit.Right = append(hit.Right, w.Form)
+			}
+			hits = append(hits, hit)
+		}
+	}
+	return hits
+}
+
+func sortKey(words []string) string {
+	keys := make([]string, len(words))
+	for k, w := range words {
+		keys[k] = lexicon.TonelessKey(w)
+	}
+	return strings.Join(keys, " ")
+}
+
+func sortHits(hits []Hit, by string) error {
+	var key func(h Hit) string
+	switch by {
+	case "":
+		return nil
+	case "left":
+		key = func(h Hit) string {
+			left := slices.Clone(h.Left)
+			slices.Reverse(left)
+			return sortKey(left)
+		}
+	case "right":
+		key = func(h Hit) string { return sortKey(h.Right) }
+	default:
+		return fmt.Errorf("cannot sort by %q: expected left or right", by)
+	}
+	slices.SortStableFunc(hits, func(a, b Hit) int {
+		return strings.Compare(key(a), key(b))
+	})
+	return nil
+}
+
+func writeKWIC(out io.Writer, hits []Hit, width int, langs []string) error {
+	w := bufio.NewWriter(out)
+	for _, hit := range hits {
+		left := truncateLeft(strings.Join(hit.Left, " "), width)
+		right := truncateRight(strings.Join(hit.Right, " "), width)
+		padding := strings.Repeat(" ", width-uniseg.StringWidth(left))
+		if _, err := fmt.Fprintf(w, "%s%s  %s  %s\n", padding, left, hit.Keyword, right); err != nil {
+			return err
+		}
+		for _, lang := range langs {
+			if text := hit.Sentence.Comment("text_" + lang); text != "" {
+				if _, err := fmt.Fprintf(w, "    %s: ‘%s’\n", lang, text); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return w.Flush()
+}
+
+func truncateLeft(s string, width int) string {
+	for uniseg.StringWidth(s) > width {
+		_, s, _, _ = uniseg.FirstGraphemeClusterInString(s, -1)
+	}
+	return s
+}
+
+func truncateRight(s string, width int) string {
+	var b strings.Builder
+	g := uniseg.NewGraphemes(s)
+	for g.Next() && uniseg.StringWidth(b.String())+g.Width() <= width {
+		b.WriteString(g.Str())
+	}
+	return b.String()
+}
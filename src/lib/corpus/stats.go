@@ -0,0 +1,282 @@
+// Corpus statistics and lexicon coverage.
+//
+// Each word (excluding punctuation) is matched to lexicon rows by its lemma,
+// else by its lexicon.TonelessKey, preferring a row whose UDPos equals the word UPOS.
+// Corpus counts of matched rows can then be binned into the 1 (most frequent)
+// to 6 (least frequent) scale of the lexicon Frequency column.
+
+package corpus
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/zokwezo/sango/src/lib/lexicon"
+)
+
+type Count struct {
+	Key   string
+	Count int
+}
+
+type RowCount struct {
+	Index int // into the rows passed to ComputeStats
+	Row   lexicon.DictRow
+	Count int
+}
+
+// A word whose UPOS differs from the UDPos of every lexicon row matching it.
+type Mismatch struct {
+	Lemma string
+	UPOS  string
+	UDPos []string
+	Count int
+}
+
+type Stats struct {
+	Tokens         int
+	Types          int // distinct lowercase forms
+	TonelessTypes  int // distinct toneless forms
+	UPOS           []Count
+	Lemmas         []Count
+	Unmatched      []Count // lowercase forms of words with no lexicon row
+	UnmatchedCount int     // number of tokens with no lexicon row
+	Mismatches     []Mismatch
+	Attested       []RowCount
+	Unattested     lexicon.DictRows
+}
+
+// Counts are sorted in decreasing order, with ties broken by key.
+func ComputeStats(sentences []Sentence, rows lexicon.DictRows) Stats {
+	return computeStats(sentences, rows)
+}
+
+// Bins a corpus count (out of numTokens) into the lexicon Frequency scale, or 0 if unattested.
+func SuggestedFrequency(count, numTokens int) int {
+	if count == 0 || numTokens == 0 {
+		return 0
+	}
+	perMillion := 1e6 * float64(count) / float64(numTokens)
+	for k, threshold := range frequencyThresholdsPerMillion {
+		if perMillion >= threshold {
+			return k + 1
+		}
+	}
+	return len(frequencyThresholdsPerMillion) + 1
+}
+
+// Writes a human-readable report, listing at most limit entries per table (0 for all).
+func WriteStats(out io.Writer, stats Stats, limit int) error {
+	return writeStats(out, stats, limit)
+}
+
+// Writes one line for each attested lexicon row whose Frequency differs from the corpus.
+func WriteFrequencyValidation(out io.Writer, stats Stats) error {
+	return writeFrequencyValidation(out, stats)
+}
+
+// Writes the lexicon rows as CSV (see lexicon/README.md), with the Frequency of attested rows
+// recomputed, except for alternate spellings.
+func WriteRecomputedFrequencies(out io.Writer, stats Stats, rows lexicon.DictRows) error {
+	return writeRecomputedFrequencies(out, stats, rows)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+var frequencyThresholdsPerMillion = []float64{10000, 3000, 1000, 300, 100}
+
+type mismatchKey struct {
+	lemma, upos string
+}
+
+// Prefers rows with the same UDPos as the word UPOS, then the more frequent row.
+func isBetterMatch(row, best lexicon.DictRow, upos string) bool {
+	if (row.UDPos == upos) != (best.UDPos == upos) {
+		return row.UDPos == upos
+	}
+	return row.Frequency < best.Frequency
+}
+
+func sortedCounts(counts map[string]int) []Count {
+	var sorted []Count
+	for key, count := range counts {
+		sorted = append(sorted, Count{key, count})
+	}
+	slices.SortFunc(sorted, func(a, b Count) int {
+		return cmp.Or(b.Count-a.Count, strings.Compare(a.Key, b.Key))
+	})
+	return sorted
+}
+
+func computeStats(sentences []Sentence, rows lexicon.DictRows) Stats {
+	rowsFromLemma := map[string][]int{}
+	rowsFromToneless := map[string][]int{}
+	for k, row := range rows {
+		if row.Toneless == "" {
+			continue
+		}
+		lemma := strings.ToLower(row.Lemma)
+		rowsFromLemma[lemma] = append(rowsFromLemma[lemma], k)
+		key := row.TonelessKey()
+		rowsFromToneless[key] = append(rowsFromToneless[key], k)
+	}
+
+	var stats Stats
+	forms := map[string]int{}
+	toneless := map[string]int{}
+	upos := map[string]int{}
+	lemmas := map[string]int{}
+	unmatched := map[string]int{}
+	rowCounts := make([]int, len(rows))
+	mismatches := map[mismatchKey]*Mismatch{}
+	for _, s := range sentences {
+		for _, word := range s.Words() {
+			if word.UPOS == "PUNCT" {
+				continue
+			}
+			stats.Tokens++
+			form := strings.ToLower(word.Form)
+			forms[form]++
+			toneless[lexicon.TonelessKey(form)]++
+			upos[word.UPOS]++
+			lemma := strings.ToLower(word.Lemma)
+			if lemma == "" {
+				lemma = form
+			}
+			lemmas[lemma]++
+
+			matches := rowsFromLemma[lemma]
+			if len(matches) == 0 {
+				matches = rowsFromToneless[lexicon.TonelessKey(lemma)]
+			}
+			if len(matches) == 0 {
+				matches = rowsFromToneless[lexicon.TonelessKey(form)]
+			}
+			if len(matches) == 0 {
+				unmatched[form]++
+				stats.UnmatchedCount++
+				continue
+			}
+			best := matches[0]
+			for _, k := range matches[1:] {
+				if isBetterMatch(rows[k], rows[best], word.UPOS) {
+					best = k
+				}
+			}
+			rowCounts[best]++
+			if rows[best].UDPos != word.UPOS && word.UPOS != "" {
+				key := mismatchKey{lemma, word.UPOS}
+				if mismatches[key] == nil {
+					m := &Mismatch{Lemma: lemma, UPOS: word.UPOS}
+					for _, k := range matches {
+						if !slices.Contains(m.UDPos, rows[k].UDPos) {
+							m.UDPos = append(m.UDPos, rows[k].UDPos)
+						}
+					}
+					mismatches[key] = m
+				}
+				mismatches[key].Count++
+			}
+		}
+	}
+
+	stats.Types = len(forms)
+	stats.TonelessTypes = len(toneless)
+	stats.UPOS = sortedCounts(upos)
+	stats.Lemmas = sortedCounts(lemmas)
+	stats.Unmatched = sortedCounts(unmatched)
+	for _, m := range mismatches {
+		stats.Mismatches = append(stats.Mismatches, *m)
+	}
+	slices.SortFunc(stats.Mismatches, func(a, b Mismatch) int {
+		return cmp.Or(b.Count-a.Count, strings.Compare(a.Lemma, b.Lemma), strings.Compare(a.UPOS, b.UPOS))
+	})
+	for k, row := range rows {
+		if row.Toneless == "" {
+			continue
+		}
+		if rowCounts[k] == 0 {
+			stats.Unattested = append(stats.Unattested, row)
+		} else {
+			stats.Attested = append(stats.Attested, RowCount{k, row, rowCounts[k]})
+		}
+	}
+	slices.SortStableFunc(stats.Attested, func(a, b RowCount) int { return b.Count - a.Count })
+	return stats
+}
+
+func writeStats(out io.Writer, stats Stats, limit int) error {
+	w := bufio.NewWriter(out)
+	upTo := func(n int) int {
+		if limit > 0 && limit < n {
+			return limit
+		}
+		return n
+	}
+	percent := func(count int) float64 {
+		return 100 * float64(count) / float64(max(1, stats.Tokens))
+	}
+	writeCounts := func(title string, counts []Count) {
+		fmt.Fprintf(w, "\n%s (%v)\n", title, len(counts))
+		for _, c := range counts[:upTo(len(counts))] {
+			fmt.Fprintf(w, "%v\t%.2f%%\t%s\n", c.Count, percent(c.Count), c.Key)
+		}
+	}
+
+	fmt.Fprintf(w, "TOKENS\t%v\n", stats.Tokens)
+	fmt.Fprintf(w, "TYPES\t%v\n", stats.Types)
+	fmt.Fprintf(w, "TONELESS TYPES\t%v\n", stats.TonelessTypes)
+	fmt.Fprintf(w, "LEXICON COVERAGE\t%.2f%% of tokens\n", percent(stats.Tokens-stats.UnmatchedCount))
+	writeCounts("UPOS", stats.UPOS)
+	writeCounts("LEMMAS", stats.Lemmas)
+	writeCounts("TOKENS WITH NO LEXICON ROW", stats.Unmatched)
+
+	fmt.Fprintf(w, "\nUPOS NOT IN LEXICON UDPOS (%v)\n", len(stats.Mismatches))
+	for _, m := range stats.Mismatches[:upTo(len(stats.Mismatches))] {
+		fmt.Fprintf(w, "%v\t%s\t%s\tlexicon: %s\n", m.Count, m.Lemma, m.UPOS, strings.Join(m.UDPos, ","))
+	}
+
+	fmt.Fprintf(w, "\nLEXICON ROWS NEVER ATTESTED (%v)\n", len(stats.Unattested))
+	for _, row := range stats.Unattested[:upTo(len(stats.Unattested))] {
+		fmt.Fprintf(w, "%s\t%s\t%v\t%s\n", row.Lemma, row.UDPos, row.Frequency, row.EnglishTranslation)
+	}
+	return w.Flush()
+}
+
+func writeFrequencyValidation(out io.Writer, stats Stats) error {
+	w := bufio.NewWriter(out)
+	fmt.Fprintf(w, "LEMMA\tUDPOS\tCOUNT\tLEXICON\tCORPUS\n")
+	for _, rc := range stats.Attested {
+		suggested := SuggestedFrequency(rc.Count, stats.Tokens)
+		if suggested != rc.Row.Frequency {
+			fmt.Fprintf(w, "%s\t%s\t%v\t%v\t%v\n", rc.Row.Lemma, rc.Row.UDPos, rc.Count, rc.Row.Frequency, suggested)
+		}
+	}
+	return w.Flush()
+}
+
+func writeRecomputedFrequencies(out io.Writer, stats Stats, rows lexicon.DictRows) error {
+	w := bufio.NewWriter(out)
+	counts := make([]int, len(rows))
+	for _, rc := range stats.Attested {
+		counts[rc.Index] = rc.Count
+	}
+	fmt.Fprintln(w, `"Toneless","Heightless","Lemma","Canonical","UDPos","UDFeature","Category","Frequency","EnglishTranslation","EnglishDefinition"`)
+	for k, r := range rows {
+		if r.Toneless == "" {
+			continue
+		}
+		frequency := r.Frequency
+		if counts[k] > 0 && r.Category != "ALT SP FOR" {
+			frequency = SuggestedFrequency(counts[k], stats.Tokens)
+		}
+		fmt.Fprintf(w, "%q,%q,%q,%q,%q,%q,%q,%v,%q,%q\n", r.Toneless, r.Heightless, r.Lemma, r.Canonical,
+			r.UDPos, r.UDFeature, r.Category, frequency, r.EnglishTranslation, r.EnglishDefinition)
+	}
+	return w.Flush()
+}
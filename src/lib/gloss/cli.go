@@ -0,0 +1,56 @@
+package gloss
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zokwezo/sango/src/lib/corpus"
+)
+
+func Init(rootCmd *cobra.Command) {
+	glossCmd.Flags().StringVar(&formatFlagValue, "format", "conllu", "Output format: conllu or interlinear.")
+	rootCmd.AddCommand(glossCmd)
+}
+
+var (
+	formatFlagValue string
+
+	glossCmd = &cobra.Command{
+		Use:   "gloss",
+		Short: "Read Sango sentences (one per line) from stdin, gloss each word from the lexicon, then write to stdout",
+		Args:  cobra.MaximumNArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			if formatFlagValue != "conllu" && formatFlagValue != "interlinear" {
+				log.Fatalf("Unknown --format %q", formatFlagValue)
+			}
+			in := bufio.NewScanner(os.Stdin)
+			out := bufio.NewWriter(os.Stdout)
+			defer out.Flush()
+			for in.Scan() {
+				text := strings.TrimSpace(in.Text())
+				if text == "" {
+					continue
+				}
+				words := GlossSango(strings.NewReader(text))
+				var err error
+				switch formatFlagValue {
+				case "conllu":
+					err = corpus.Write(out, []corpus.Sentence{AsSentence(text, words)})
+				case "interlinear":
+					if err = WriteInterlinear(out, words, ""); err == nil {
+						_, err = out.WriteString("\n")
+					}
+				}
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+			if err := in.Err(); err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+)
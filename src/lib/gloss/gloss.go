@@ -0,0 +1,263 @@
+// Glosses Sango text word by word from the lexicon.
+//
+// Each word is matched against lexicon entries with the same lexicon.TonelessKey,
+// and the sense is chosen by preferring (in order) an exact match of the written
+// pitch and vowel height, a part of speech compatible with the previous word,
+// and the most frequent entry.
+
+package gloss
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/rivo/uniseg"
+	"github.com/zokwezo/sango/src/lib/corpus"
+	"github.com/zokwezo/sango/src/lib/lexicon"
+	"github.com/zokwezo/sango/src/lib/tokenize"
+	"golang.org/x/text/unicode/norm"
+)
+
+type Word struct {
+	Form        string          // as written, in NFC
+	Row         lexicon.DictRow // lexicon entry chosen, if Found
+	Found       bool
+	UPOS        string
+	Gloss       string // "?" if not found in the lexicon
+	BoundToNext bool   // prefix written attached to the next word (e.g. subject marker a-)
+}
+
+// Glosses each word (including numbers and punctuation, but not whitespace) of the input.
+func GlossSango(in io.Reader) []Word {
+	return glossSango(in)
+}
+
+// Converts glossed words into a CoNLL-U sentence with MISC Gloss= on each token.
+func AsSentence(text string, words []Word) corpus.Sentence {
+	return asSentence(text, words)
+}
+
+// Writes Leipzig-style three-line interlinear glossed text.
+// If translation is empty, the glosses are joined as a literal translation.
+func WriteInterlinear(out io.Writer, words []Word, translation string) error {
+	return writeInterlinear(out, words, translation)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+// The subject marker a- is written attached to the verb and is not itself a lexicon entry.
+var subjectMarker = lexicon.DictRow{
+	Toneless:           "a",
+	Heightless:         "a",
+	Lemma:              "a",
+	Canonical:          "ha_",
+	UDPos:              "PRON",
+	UDFeature:          "Case=Nom|Person=3|Prefix=Yes|PronType=Art",
+	Category:           "WHO",
+	Frequency:          1,
+	EnglishTranslation: "he-she-it",
+	EnglishDefinition:  "subject marker",
+}
+
+// Parts of speech likely to follow a word with a given part of speech.
+var likelyNextUPOS = map[string][]string{
+	"ADJ":   {"NOUN", "PROPN"},
+	"ADP":   {"NOUN", "PROPN", "PRON", "VERB"},
+	"AUX":   {"VERB"},
+	"CCONJ": {"NOUN", "PROPN", "PRON", "VERB"},
+	"DET":   {"NOUN", "PROPN", "ADP", "PUNCT"},
+	"NUM":   {"NOUN"},
+	"PRON":  {"VERB", "AUX", "ADP", "DET"},
+	"PROPN": {"VERB", "ADP", "DET"},
+	"NOUN":  {"VERB", "ADP", "DET", "ADJ"},
+	"SCONJ": {"PRON", "NOUN", "PROPN"},
+	"VERB":  {"NOUN", "PRON", "ADP", "ADV", "PROPN"},
+}
+
+// Keyed by lexicon.TonelessKey.
+var lexiconRowsFromToneless = func() map[string]lexicon.DictRows {
+	out := map[string]lexicon.DictRows{}
+	for _, row := range lexicon.LexiconRows() {
+		if row.Toneless != "" {
+			key := row.TonelessKey()
+			out[key] = append(out[key], row)
+		}
+	}
+	return out
+}()
+
+var toTokenized = strings.NewReplacer(
+	"ɛ", "x", "Ɛ", "X", "ɔ", "c", "Ɔ", "C", "\u0302", "j", "\u0308", "q", "-", "", " ", "")
+
+// Both arguments in tokenized (ASCII-substituted NFD) form.
+func spellingMatch(tokenized, lemma string) int {
+	if tokenized == lemma {
+		return 2 // same pitch and vowel height
+	}
+	heightless := strings.NewReplacer("x", "e", "c", "o")
+	if heightless.Replace(tokenized) == heightless.Replace(lemma) {
+		return 1 // same pitch
+	}
+	return 0
+}
+
+func chooseSense(tokenized string, candidates lexicon.DictRows, prevUPOS string) lexicon.DictRow {
+	w := strings.ToLower(tokenized)
+	lemmaOf := func(row lexicon.DictRow) string {
+		return strings.ToLower(toTokenized.Replace(norm.NFD.String(row.Lemma)))
+	}
+	inContext := func(row lexicon.DictRow) int {
+		if slices.Contains(likelyNextUPOS[prevUPOS], row.UDPos) {
+			return 1
+		}
+		return 0
+	}
+	ranked := slices.Clone(candidates)
+	slices.SortStableFunc(ranked, func(lhs, rhs lexicon.DictRow) int {
+		if d := spellingMatch(w, lemmaOf(rhs)) - spellingMatch(w, lemmaOf(lhs)); d != 0 {
+			return d
+		}
+		if d := inContext(rhs) - inContext(lhs); d != 0 {
+			return d
+		}
+		return lhs.Frequency - rhs.Frequency
+	})
+	return ranked[0]
+}
+
+func sangoWord(form string, row lexicon.DictRow) Word {
+	gloss := row.EnglishTranslation
+	if gloss == "" {
+		gloss = "?"
+	}
+	return Word{
+		Form:  form,
+		Row:   row,
+		Found: true,
+		UPOS:  row.UDPos,
+		Gloss: gloss,
+	}
+}
+
+func glossSango(in io.Reader) []Word {
+	text, err := io.ReadAll(in)
+	if err != nil {
+		return nil
+	}
+	s, tokens := tokenize.TokenizeSango(bytes.NewReader(text))
+	if s == nil {
+		return nil
+	}
+	texts := tokenize.TokenTexts(bytes.NewReader(text))
+	var words []Word
+	prevUPOS := ""
+	for k, token := range tokens {
+		w := (*s)[token.Begin:token.End]
+		var word Word
+		switch token.REindex {
+		case 0: // whitespace
+			continue
+		case 1: // number
+			word = Word{Form: w, UPOS: "NUM", Gloss: w}
+		case 2: // punctuation
+			w = norm.NFC.String(w)
+			word = Word{Form: w, UPOS: "PUNCT", Gloss: w}
+		case 3: // Sango
+			if candidates := lexiconRowsFromToneless[lexicon.TonelessKey(texts[k])]; len(candidates) > 0 {
+				word = sangoWord(texts[k], chooseSense(w, candidates, prevUPOS))
+				break
+			}
+			if len(w) > 1 && (w[0] == 'a' || w[0] == 'A') && !strings.ContainsAny(w[1:2], "jq") {
+				var verbs lexicon.DictRows
+				for _, row := range lexiconRowsFromToneless[lexicon.TonelessKey(texts[k][1:])] {
+					if row.UDPos == "VERB" {
+						verbs = append(verbs, row)
+					}
+				}
+				if len(verbs) > 0 {
+					marker := sangoWord(texts[k][:1], subjectMarker)
+					marker.BoundToNext = true
+					words = append(words, marker)
+					word = sangoWord(texts[k][1:], chooseSense(w[1:], verbs, marker.UPOS))
+					break
+				}
+			}
+			word = Word{Form: texts[k], UPOS: "X", Gloss: "?"}
+		default: // other languages and symbols
+			word = Word{Form: norm.NFC.String(w), UPOS: "X", Gloss: "?"}
+		}
+		words = append(words, word)
+		prevUPOS = word.UPOS
+	}
+	return words
+}
+
+func asSentence(text string, words []Word) corpus.Sentence {
+	sentence := corpus.Sentence{}
+	if text != "" {
+		sentence.SetComment("text", text)
+	}
+	for k, word := range words {
+		token := corpus.Token{
+			ID:   fmt.Sprint(k + 1),
+			Form: word.Form,
+			UPOS: word.UPOS,
+		}
+		if word.Found {
+			token.Lemma = word.Row.Lemma
+			token.Feats = word.Row.UDFeature
+		}
+		token.SetMisc("Gloss", word.Gloss)
+		sentence.Tokens = append(sentence.Tokens, token)
+	}
+	return sentence
+}
+
+func writeInterlinear(out io.Writer, words []Word, translation string) error {
+	// Group bound prefixes with their host word, as in Leipzig rule 2 (a-hûnda / he.she.it-ask).
+	var forms, glosses, literal []string
+	form, gloss := "", ""
+	for _, word := range words {
+		g := strings.ReplaceAll(word.Gloss, "-", ".")
+		form += word.Form
+		gloss += g
+		if word.UPOS != "PUNCT" {
+			literal = append(literal, strings.ReplaceAll(word.Gloss, "-", " "))
+		}
+		if word.BoundToNext {
+			form += "-"
+			gloss += "-"
+			continue
+		}
+		forms = append(forms, form)
+		glosses = append(glosses, gloss)
+		form, gloss = "", ""
+	}
+	if translation == "" {
+		translation = strings.Join(literal, " ")
+	}
+	w := bufio.NewWriter(out)
+	var line1, line2 strings.Builder
+	for k := range forms {
+		width := max(uniseg.StringWidth(forms[k]), uniseg.StringWidth(glosses[k]))
+		if k > 0 {
+			line1.WriteString(" ")
+			line2.WriteString(" ")
+		}
+		line1.WriteString(forms[k])
+		line2.WriteString(glosses[k])
+		if k+1 < len(forms) {
+			line1.WriteString(strings.Repeat(" ", width-uniseg.StringWidth(forms[k])))
+			line2.WriteString(strings.Repeat(" ", width-uniseg.StringWidth(glosses[k])))
+		}
+	}
+	fmt.Fprintln(w, line1.String())
+	fmt.Fprintln(w, line2.String())
+	fmt.Fprintf(w, "‘%s’\n", translation)
+	return w.Flush()
+}
@@ -0,0 +1,73 @@
+package gloss
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func glossesOf(words []Word) string {
+	var glosses []string
+	for _, w := range words {
+		glosses = append(glosses, w.Form+"="+w.Gloss)
+	}
+	return strings.Join(glosses, " ")
+}
+
+func TestGlossSangoPrefersWrittenPitch(t *testing.T) {
+	words := GlossSango(strings.NewReader("Tɛrɛ na Ngûru."))
+	expect := "Tɛrɛ=spider na=at-in-with Ngûru=pig .=."
+	if actual := glossesOf(words); actual != expect {
+		t.Errorf("\nexpect: %s\nactual: %s", expect, actual)
+	}
+	words = GlossSango(strings.NewReader("tɛrɛ̂"))
+	if len(words) != 1 || words[0].Row.Lemma != "tɛrɛ̂" {
+		t.Errorf("tɛrɛ̂ glossed as %v", words)
+	}
+}
+
+func TestGlossSangoSplitsSubjectMarker(t *testing.T) {
+	words := GlossSango(strings.NewReader("Tɛrɛ ahûnda"))
+	expect := "Tɛrɛ=spider a=he-she-it hûnda=ask"
+	if actual := glossesOf(words); actual != expect {
+		t.Errorf("\nexpect: %s\nactual: %s", expect, actual)
+	}
+	if !words[1].BoundToNext || words[2].UPOS != "VERB" {
+		t.Errorf("bad subject marker split: %v", words)
+	}
+}
+
+func TestGlossSangoUnknownWord(t *testing.T) {
+	words := GlossSango(strings.NewReader("zzz"))
+	if len(words) != 1 || words[0].Found || words[0].Gloss != "?" {
+		t.Errorf("zzz glossed as %v", words)
+	}
+	// Written as is, though tokenized like a Sango word with vowels x and c.
+	if words := GlossSango(strings.NewReader("taxi CFA")); glossesOf(words) != "taxi=? CFA=?" {
+		t.Errorf("taxi CFA glossed as %v", words)
+	}
+}
+
+func TestAsSentence(t *testing.T) {
+	s := AsSentence("Tɛrɛ ahûnda", GlossSango(strings.NewReader("Tɛrɛ ahûnda")))
+	if s.Comment("text") != "Tɛrɛ ahûnda" || len(s.Tokens) != 3 {
+		t.Fatalf("bad sentence %v", s)
+	}
+	if tok := s.Tokens[2]; tok.ID != "3" || tok.Lemma != "hûnda" || tok.Feats != "Subcat=Tran" || tok.Misc != "Gloss=ask" {
+		t.Errorf("bad token %v", tok)
+	}
+}
+
+func TestWriteInterlinear(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteInterlinear(&out, GlossSango(strings.NewReader("Tɛrɛ ahûnda kɔ̂bɛ")), ""); err != nil {
+		t.Fatal(err)
+	}
+	expect := "" +
+		"Tɛrɛ   a-hûnda       kɔ̂bɛ\n" +
+		"spider he.she.it-ask food\n" +
+		"‘spider he she it ask food’\n"
+	if out.String() != expect {
+		t.Errorf("\nexpect:\n%s\nactual:\n%s", expect, out.String())
+	}
+}
@@ -0,0 +1,53 @@
+package hyphenate
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func Init(rootCmd *cobra.Command) {
+	hyphenateCmd.Flags().StringVar(&hyphenFlagValue, "hyphen", "-", "Inserted at each break point, e.g. a soft hyphen (U+00AD).")
+	hyphenateCmd.Flags().IntVar(&leftMinFlagValue, "left_min", DefaultOptions.LeftMin, "Minimum number of letters before the first break point of a word.")
+	hyphenateCmd.Flags().IntVar(&rightMinFlagValue, "right_min", DefaultOptions.RightMin, "Minimum number of letters after the last break point of a word.")
+	hyphenateCmd.AddCommand(patternsCmd)
+	rootCmd.AddCommand(hyphenateCmd)
+}
+
+var (
+	hyphenFlagValue   string
+	leftMinFlagValue  int
+	rightMinFlagValue int
+
+	hyphenateCmd = &cobra.Command{
+		Use:     "hyphenate",
+		Short:   "Read Sango text from stdin, insert a hyphen at each syllable break point of each word, then write to stdout",
+		Example: "  echo 'Ngbangbo ayeke kɔ̂bɛ' | sango hyphenate",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			b, err := io.ReadAll(bufio.NewReader(os.Stdin))
+			if err != nil {
+				log.Fatal(err)
+			}
+			options := Options{LeftMin: leftMinFlagValue, RightMin: rightMinFlagValue}
+			if _, err := os.Stdout.WriteString(HyphenateText(string(b), hyphenFlagValue, options)); err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+
+	patternsCmd = &cobra.Command{
+		Use:     "patterns",
+		Short:   "Write the Sango hyphenation patterns for TeX to stdout",
+		Example: "  sango hyphenate patterns > hyph-sg.tex",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := WritePatterns(os.Stdout); err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+)
@@ -0,0 +1,227 @@
+// Sango Syllabification and Hyphenation
+//
+// Every Sango syllable is (C)V(n): an optional consonant, which may be written
+// with several letters (e.g. ngb, kp, mb, ny), then a vowel with its tone marks,
+// then an n (or ñ) if the vowel is nasal. A word may be broken before any
+// syllable but its first, e.g. ngba-ngbo, kɔ̂-bɛ, and hôn-tï, as found by
+// parsing it into SSEs. Words that are not Sango (e.g. French or English loans
+// that are not spelled as Sango syllables) are never broken.
+//
+// The same rules are written as TeX patterns (for use with \patterns), so that
+// TeX can hyphenate Sango words that are not in the lexicon. Patterns are given
+// in NFC, and the combining diacritics of ɛ̂, ɛ̈, ɔ̂, and ɔ̈ (U+0302 and U+0308)
+// must be letters (with a \lccode) for them to apply.
+
+package hyphenate
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/zokwezo/sango/src/lib/sse"
+	"golang.org/x/text/unicode/norm"
+)
+
+type Options struct {
+	LeftMin, RightMin int // minimum number of letters before the first break and after the last
+}
+
+// Like TeX's \lefthyphenmin and \righthyphenmin.
+var DefaultOptions = Options{LeftMin: 2, RightMin: 2}
+
+// Returns the byte offsets in word before which it may be broken, or nil if it
+// is not a Sango word.
+func BreakPoints(word string, options Options) []int {
+	return breakPoints(word, options)
+}
+
+// Returns the word with hyphen (e.g. "-", or "\u00AD" for a soft hyphen) at each break point.
+func Hyphenate(word, hyphen string, options Options) string {
+	return hyphenate(word, hyphen, options)
+}
+
+// Returns the text with hyphen at each break point of each of its words.
+func HyphenateText(text, hyphen string, options Options) string {
+	return hyphenateText(text, hyphen, options)
+}
+
+// Returns the TeX hyphenation patterns of Sango.
+func Patterns() []string {
+	return patterns()
+}
+
+// Writes the patterns as a TeX \patterns command.
+func WritePatterns(out io.Writer) error {
+	return writePatterns(out)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+// Returns the number of letters with which each syllable of the word is written.
+func syllableLengths(word string) []int {
+	sses, err := sse.UTF8ToSSEs(word)
+	if err != nil {
+		return nil
+	}
+	var lengths []int
+	for _, x := range sses {
+		if x.Kind() != sse.KindSango {
+			return nil
+		}
+		for _, s := range x.Syllables() {
+			syllable, err := sse.NewBuilder().Append(sse.Syllable{Consonant: s.Consonant, Vowel: s.Vowel, Pitch: s.Pitch}).Build()
+			if err != nil {
+				return nil
+			}
+			var toneless strings.Builder
+			syllable.WriteAsTonelessTo(&toneless)
+			lengths = append(lengths, utf8.RuneCountInString(toneless.String()))
+		}
+	}
+	return lengths
+}
+
+func breakPoints(word string, options Options) []int {
+	lengths := syllableLengths(word)
+	if len(lengths) < 2 {
+		return nil
+	}
+
+	// The offset of each letter (not counting its diacritics), and whether it
+	// follows a letter (and not e.g. a hyphen).
+	var letters []int
+	afterLetter := map[int]bool{}
+	prevIsLetter := false
+	for offset, r := range word {
+		isLetter := false
+		for _, d := range norm.NFD.String(string(r)) {
+			if unicode.IsLetter(d) {
+				isLetter = true
+			}
+		}
+		if isLetter {
+			letters = append(letters, offset)
+			afterLetter[offset] = prevIsLetter
+		}
+		prevIsLetter = isLetter || unicode.Is(unicode.Mn, r)
+	}
+	total := 0
+	for _, n := range lengths {
+		total += n
+	}
+	if total != len(letters) {
+		return nil
+	}
+
+	var breaks []int
+	k := 0
+	for _, n := range lengths[:len(lengths)-1] {
+		k += n
+		if k >= options.LeftMin && total-k >= options.RightMin && afterLetter[letters[k]] {
+			breaks = append(breaks, letters[k])
+		}
+	}
+	return breaks
+}
+
+func hyphenate(word, hyphen string, options Options) string {
+	var s strings.Builder
+	begin := 0
+	for _, end := range breakPoints(word, options) {
+		s.WriteString(word[begin:end])
+		s.WriteString(hyphen)
+		begin = end
+	}
+	s.WriteString(word[begin:])
+	return s.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.Is(unicode.Mn, r)
+}
+
+func hyphenateText(text, hyphen string, options Options) string {
+	var s strings.Builder
+	for len(text) > 0 {
+		end := strings.IndexFunc(text, func(r rune) bool { return !isWordRune(r) })
+		if end == -1 {
+			end = len(text)
+		}
+		s.WriteString(hyphenate(text[:end], hyphen, options))
+		text = text[end:]
+		begin := strings.IndexFunc(text, isWordRune)
+		if begin == -1 {
+			begin = len(text)
+		}
+		s.WriteString(text[:begin])
+		text = text[begin:]
+	}
+	return s.String()
+}
+
+// Letters that begin a syllable, and pairs of them that spell one consonant.
+var (
+	onsetLetters = strings.Split("bdfghklmnprstvwyz", "")
+	digraphs     = []string{"gb", "kp", "mb", "mp", "mv", "nd", "ng", "ny", "nz"}
+	vowelLetters = strings.Split("aeɛioɔu", "")
+)
+
+// Each vowel letter, with and without a circumflex (high pitch) or diaeresis (mid pitch).
+func vowelForms() []string {
+	var forms []string
+	for _, v := range vowelLetters {
+		for _, diacritic := range []string{"", "\u0302", "\u0308"} {
+			forms = append(forms, norm.NFC.String(v+diacritic))
+		}
+	}
+	return forms
+}
+
+func patterns() []string {
+	var p []string
+	// Break before a consonant, but not within one.
+	for _, c := range onsetLetters {
+		p = append(p, "1"+c)
+	}
+	for _, d := range digraphs {
+		p = append(p, d[:1]+"2"+d[1:])
+	}
+	// Do not break before the n of a nasal vowel: before another consonant,
+	// at the end of a word, or written ñ.
+	for _, c := range onsetLetters {
+		if !slices.Contains(digraphs, "n"+c) {
+			p = append(p, "2n"+c)
+		}
+	}
+	p = append(p, "2n.", "2ñ")
+	// Break between vowels, and after ñ.
+	vowels := vowelForms()
+	for _, v := range vowels {
+		for _, w := range vowels {
+			p = append(p, v+"1"+w)
+		}
+		p = append(p, "ñ1"+v)
+	}
+	return p
+}
+
+func writePatterns(out io.Writer) error {
+	if _, err := fmt.Fprintln(out, `% Sango hyphenation patterns, written by "sango hyphenate patterns".`); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(out, `\patterns{`); err != nil {
+		return err
+	}
+	for _, p := range patterns() {
+		if _, err := fmt.Fprintln(out, p); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(out, "}")
+	return err
+}
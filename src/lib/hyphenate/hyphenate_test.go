@@ -0,0 +1,97 @@
+package hyphenate
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/zokwezo/sango/src/lib/lexicon"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestHyphenate(t *testing.T) {
+	for _, test := range []struct {
+		word, expect string
+		options      Options
+	}{
+		{"ngbangbo", "ngba-ngbo", DefaultOptions},
+		{"kɔ̂bɛ", "kɔ̂-bɛ", DefaultOptions},
+		{"kpângba", "kpâ-ngba", DefaultOptions},
+		{"Sêse", "Sê-se", DefaultOptions},
+		{"ayeke", "aye-ke", DefaultOptions},
+		{"ayeke", "a-ye-ke", Options{LeftMin: 1, RightMin: 1}},
+		{"lâkûî", "lâ-kûî", DefaultOptions},
+		{"lâ-kûî", "lâ-kûî", DefaultOptions},
+		{"kɔɔ", "kɔ-ɔ", Options{LeftMin: 1, RightMin: 1}},
+		{"zo", "zo", DefaultOptions},
+		{"français", "français", DefaultOptions},
+	} {
+		if actual := Hyphenate(test.word, "-", test.options); actual != test.expect {
+			t.Errorf("Hyphenate(%q, %+v)\nexpect: %q\nactual: %q", test.word, test.options, test.expect, actual)
+		}
+	}
+	if actual, expect := BreakPoints("ngbangbo", DefaultOptions), []int{4}; !slices.Equal(actual, expect) {
+		t.Errorf("bad BreakPoints %v, expected %v", actual, expect)
+	}
+	text := "Ngbangbo ayeke kɔ̂bɛ, lâ-kûî na français."
+	expect := "Ngba\u00ADngbo aye\u00ADke kɔ̂\u00ADbɛ, lâ-kûî na français."
+	if actual := HyphenateText(text, "\u00AD", DefaultOptions); actual != expect {
+		t.Errorf("bad HyphenateText\nexpect: %q\nactual: %q", expect, actual)
+	}
+}
+
+// Hyphenates a lowercase NFC word with TeX patterns, by Liang's algorithm.
+func hyphenateWithPatterns(word string, patterns []string) string {
+	letters := []rune("." + word + ".")
+	values := make([]int, len(letters)+1)
+	for _, p := range patterns {
+		var chars []rune
+		pv := []int{0}
+		for _, r := range p {
+			if unicode.IsDigit(r) {
+				pv[len(pv)-1] = int(r - '0')
+			} else {
+				chars = append(chars, r)
+				pv = append(pv, 0)
+			}
+		}
+		for i := 0; i+len(chars) <= len(letters); i++ {
+			if slices.Equal(letters[i:i+len(chars)], chars) {
+				for k, v := range pv {
+					values[i+k] = max(values[i+k], v)
+				}
+			}
+		}
+	}
+	var s strings.Builder
+	for k, r := range letters[1 : len(letters)-1] {
+		// a break before letters[k+1], but never before the first letter
+		if k > 0 && values[k+1]%2 == 1 {
+			s.WriteRune('-')
+		}
+		s.WriteRune(r)
+	}
+	return s.String()
+}
+
+func TestPatternsMatchHyphenate(t *testing.T) {
+	patterns := Patterns()
+	options := Options{LeftMin: 1, RightMin: 1}
+	numWords := 0
+	for _, r := range lexicon.LexiconRows() {
+		for _, word := range strings.FieldsFunc(r.Lemma, func(r rune) bool { return !isWordRune(r) }) {
+			word = norm.NFC.String(strings.ToLower(word))
+			if len(BreakPoints(word, options)) == 0 {
+				continue
+			}
+			numWords++
+			if actual, expect := hyphenateWithPatterns(word, patterns), Hyphenate(word, "-", options); actual != expect {
+				t.Errorf("patterns hyphenate %q as %q, expected %q", word, actual, expect)
+			}
+		}
+	}
+	if numWords < 1000 {
+		t.Errorf("only %v words hyphenated", numWords)
+	}
+}
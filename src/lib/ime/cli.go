@@ -0,0 +1,99 @@
+package ime
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func Init(rootCmd *cobra.Command) {
+	rootCmd.AddCommand(imeCmd)
+}
+
+var (
+	imeCmd = &cobra.Command{
+		Use:   "ime",
+		Short: "Type Sango with pitch dead keys (j mid, jj high, q unknown; x ɛ, c ɔ), one line at a time",
+		Long: "On a terminal, each line is edited interactively (Backspace, Ctrl-Z to undo, Ctrl-D to quit).\n" +
+			"Otherwise, keystrokes are read from stdin and the typed text is written to stdout.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+				err = runTerminal(fd, os.Stdin, os.Stdout)
+			} else {
+				err = runPipe(os.Stdin, os.Stdout)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+)
+
+const (
+	keyCtrlC = '\x03'
+	keyCtrlD = '\x04'
+)
+
+// Types each line of in, as if its bytes were keystrokes.
+func runPipe(in io.Reader, out io.Writer) error {
+	r := bufio.NewReader(in)
+	w := bufio.NewWriter(out)
+	s := State{}
+	for {
+		key, _, err := r.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if key == '\n' {
+			fmt.Fprintln(w, s.Flush().Committed())
+			s = State{}
+			continue
+		}
+		s = s.Press(key)
+	}
+	if s = s.Flush(); s.Committed() != "" {
+		fmt.Fprint(w, s.Committed())
+	}
+	return w.Flush()
+}
+
+// Redraws the current line after every keystroke, with the composing keys underlined.
+func runTerminal(fd int, in io.Reader, out io.Writer) error {
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, oldState)
+
+	r := bufio.NewReader(in)
+	s := State{}
+	for {
+		fmt.Fprintf(out, "\r\x1b[K%s\x1b[4m%s\x1b[0m", s.Committed(), s.Composing())
+		key, _, err := r.ReadRune()
+		if err == io.EOF {
+			key = keyCtrlD
+		} else if err != nil {
+			return err
+		}
+		switch key {
+		case keyCtrlC, keyCtrlD:
+			fmt.Fprintf(out, "\r\x1b[K%s\r\n", s.Flush().Committed())
+			return nil
+		case '\r', '\n':
+			fmt.Fprintf(out, "\r\x1b[K%s\r\n", s.Flush().Committed())
+			s = State{}
+		default:
+			s = s.Press(key)
+		}
+	}
+}
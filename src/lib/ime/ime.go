@@ -0,0 +1,178 @@
+// Sango input method for ASCII keyboards.
+//
+// Vowels are typed as a e i o u, with x for ɛ and c for ɔ (X and C for Ɛ and Ɔ).
+// Pitch is entered with dead keys typed before the vowel:
+//
+//	(none) low pitch   a -> a
+//	j      mid pitch   ja -> ä
+//	jj     high pitch  jja -> â   (jjj cycles back to low)
+//	q      unknown     qa -> ạ
+//
+// Dead keys wait in the composing buffer until the next key. If that key is not
+// a vowel, the dead keys are committed as typed (e.g. "hajj."), and a backslash
+// commits the next key literally (e.g. \jour for jour, or ta\xi for taxi).
+// Backspace deletes the last composing key, else the last committed grapheme,
+// and Ctrl-Z undoes the last key of any kind.
+//
+// State is an immutable value, so Press is a pure function usable by editors.
+
+package ime
+
+import (
+	"strings"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Control keys, as sent by terminals.
+const (
+	KeyBackspace = '\x7f'
+	KeyCtrlH     = '\b'   // also backspace
+	KeyUndo      = '\x1a' // Ctrl-Z
+)
+
+// Pitch of the next vowel, as set by dead keys.
+type Pitch int
+
+const (
+	PitchLow Pitch = iota
+	PitchMid
+	PitchHigh
+	PitchUnknown
+)
+
+type State struct {
+	committed string
+	composing string // dead keys typed since the last commit
+	prev      *State // before the last key, for undo
+}
+
+// Returns the state after pressing key.
+func (s State) Press(key rune) State {
+	return press(s, key)
+}
+
+// Returns the state after pressing each key in turn.
+func (s State) Type(keys string) State {
+	for _, key := range keys {
+		s = s.Press(key)
+	}
+	return s
+}
+
+// Returns the state with the composing keys committed as typed, followed by text as is
+// (e.g. for pasting or recalling history).
+func (s State) Insert(text string) State {
+	prev := s
+	s = commit(flush(s), text)
+	s.prev = &prev
+	return s
+}
+
+// Returns the text committed so far, in NFC.
+func (s State) Committed() string { return s.committed }
+
+// Returns the keys typed but not yet committed.
+func (s State) Composing() string { return s.composing }
+
+// Returns the pitch the composing dead keys would give the next vowel.
+func (s State) Pitch() Pitch { return pitchOf(s.composing) }
+
+// Returns the state with the composing keys committed as typed.
+func (s State) Flush() State {
+	return flush(s)
+}
+
+// Returns the state before the last key, or s itself if there is none.
+func (s State) Undo() State {
+	if s.prev == nil {
+		return s
+	}
+	return *s.prev
+}
+
+// Returns the text produced by typing keys, e.g. "kjjcbx" -> "kɔ̂bɛ".
+func Type(keys string) string {
+	return State{}.Type(keys).Flush().Committed()
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+var vowels = map[rune]string{
+	'a': "a", 'e': "e", 'i': "i", 'o': "o", 'u': "u", 'x': "ɛ", 'c': "ɔ",
+	'A': "A", 'E': "E", 'I': "I", 'O': "O", 'U': "U", 'X': "Ɛ", 'C': "Ɔ",
+}
+
+var diacritics = map[Pitch]string{
+	PitchLow:     "",
+	PitchMid:     "\u0308",
+	PitchHigh:    "\u0302",
+	PitchUnknown: "\u0323",
+}
+
+func pitchOf(composing string) Pitch {
+	if strings.HasSuffix(composing, "q") {
+		return PitchUnknown
+	}
+	return Pitch(strings.Count(composing, "j") % 3)
+}
+
+func isDeadKey(key rune) bool {
+	return key == 'j' || key == 'q'
+}
+
+func commit(s State, text string) State {
+	s.committed = norm.NFC.String(s.committed + text)
+	s.composing = ""
+	return s
+}
+
+func flush(s State) State {
+	if s.composing == "" {
+		return s
+	}
+	prev := s
+	s = commit(s, strings.TrimPrefix(s.composing, `\`))
+	s.prev = &prev
+	return s
+}
+
+func press(s State, key rune) State {
+	prev := s
+	switch {
+	case key == KeyUndo:
+		return s.Undo()
+	case key == KeyBackspace || key == KeyCtrlH:
+		if s.composing != "" {
+			s.composing = s.composing[:len(s.composing)-1]
+		} else if s.committed != "" {
+			s.committed = deleteLastGrapheme(s.committed)
+		} else {
+			return s
+		}
+	case s.composing == `\`:
+		s = commit(s, string(key))
+	case key == '\\':
+		s = commit(s, s.composing)
+		s.composing = `\`
+	case isDeadKey(key) && !strings.Contains(s.composing, "q"):
+		s.composing += string(key)
+	case vowels[key] != "":
+		s = commit(s, vowels[key]+diacritics[pitchOf(s.composing)])
+	default:
+		s = commit(s, s.composing+string(key))
+	}
+	s.prev = &prev
+	return s
+}
+
+func deleteLastGrapheme(s string) string {
+	last := 0
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		last, _ = g.Positions()
+	}
+	return s[:last]
+}
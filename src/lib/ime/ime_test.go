@@ -0,0 +1,52 @@
+package ime
+
+import (
+	"testing"
+)
+
+func TestType(t *testing.T) {
+	for _, test := range []struct {
+		keys, expected string
+	}{
+		{"kjjcbx", "kɔ̂bɛ"},
+		{"MBjjXNGjX", "MBƐ̂NGƐ̈"},
+		{"ngbjangbja", "ngbängbä"},
+		{"jjjaqa", "aạ"},
+		{"ajanjjan", "aänân"},
+		{`\jour \qui`, "jour qui"},
+		{"hajj.", "hajj."},
+		{`ta\xi`, "taxi"},
+		{`\\`, `\`},
+		{"jj", "jj"},
+		{"ajj\x7fo", "aö"},
+		{"kc\x7fo", "ko"},
+		{"kjc\x7f\x7fo", "o"},
+		{"kjjc\x1ao", "kô"},
+		{"kjjc\x1a\x1ao", "kö"},
+		{"\x7f\x1a", ""},
+	} {
+		if actual := Type(test.keys); actual != test.expected {
+			t.Errorf("Type(%q) = %q, expected %q", test.keys, actual, test.expected)
+		}
+	}
+}
+
+func TestState(t *testing.T) {
+	s := State{}.Type("kjj")
+	if s.Committed() != "k" || s.Composing() != "jj" || s.Pitch() != PitchHigh {
+		t.Errorf("after kjj: %q %q %v", s.Committed(), s.Composing(), s.Pitch())
+	}
+	if s.Press('q').Pitch() != PitchUnknown {
+		t.Errorf("after kjjq: %v", s.Press('q').Pitch())
+	}
+	// Press is pure: s is unchanged by the states derived from it.
+	if c := s.Press('c'); c.Committed() != "kɔ̂" || s.Committed() != "k" {
+		t.Errorf("after kjjc: %q, after kjj: %q", c.Committed(), s.Committed())
+	}
+	if i := s.Insert("taxi"); i.Committed() != "kjjtaxi" || i.Undo().Composing() != "jj" {
+		t.Errorf("after insert: %q", i.Committed())
+	}
+	if u := s.Press('c').Undo().Undo(); u.Committed() != "k" || u.Composing() != "j" {
+		t.Errorf("after undo undo: %q %q", u.Committed(), u.Composing())
+	}
+}
@@ -2,9 +2,12 @@ package lexicon
 
 import (
 	"fmt"
+	"log"
 	"regexp"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/zokwezo/sango/src/lib/sse"
 )
 
 func Init(rootCmd *cobra.Command) {
@@ -16,9 +19,20 @@ func Init(rootCmd *cobra.Command) {
 	lookupCmd.Flags().StringVar(&categoryFlagValue, "category", "", "Returns values only where this regexp partially matches category.")
 	lookupCmd.Flags().StringVar(&englishTranslationFlagValue, "english_translation", "", "Returns values only where this regexp partially matches english translation.")
 	lookupCmd.Flags().StringVar(&englishDefinitionFlagValue, "english_definition", "", "Returns values only where this regexp partially matches english definition.")
+	lookupCmd.Flags().StringVar(&patternFlagValue, "pattern", "", "Returns values only where this syllable pattern (e.g. \"H L\", \"* N\", \"* P *\") matches the whole word.")
 	lookupCmd.Flags().IntVar(&frequencyMinFlagValue, "frequency_min", 1, "Returns values only where frequency_min <= row.frequency.")
 	lookupCmd.Flags().IntVar(&frequencyMaxFlagValue, "frequency_max", 9, "Returns values only where frequency_max >= row.frequency.")
 	lexiconCmd.AddCommand(lookupCmd)
+	minimalPairsCmd.Flags().StringSliceVar(&contrastFlagValue, "contrast", []string{"pitch", "height", "nasality", "consonant"}, "Features in which the entries of a set may differ.")
+	minimalPairsCmd.Flags().IntVar(&minimalFrequencyMaxFlagValue, "frequency_max", 8, "Considers only rows where row.frequency <= frequency_max (9 = alternate spellings).")
+	lexiconCmd.AddCommand(minimalPairsCmd)
+	rhymesCmd.Flags().IntVar(&syllablesFlagValue, "syllables", 1, "Number of final syllables that must rhyme (1 = the final vowel and its tone).")
+	rhymesCmd.Flags().BoolVar(&matchHeightFlagValue, "match_height", false, "Whether e and ɛ (o and ɔ) must also match.")
+	rhymesCmd.Flags().BoolVar(&matchNasalityFlagValue, "match_nasality", false, "Whether nasal and oral vowels must also match.")
+	rhymesCmd.Flags().IntVar(&rhymeFrequencyMaxFlagValue, "frequency_max", 8, "Considers only rows where row.frequency <= frequency_max (9 = alternate spellings).")
+	lexiconCmd.AddCommand(rhymesCmd)
+	melodyCmd.Flags().IntVar(&rhymeFrequencyMaxFlagValue, "frequency_max", 8, "Considers only rows where row.frequency <= frequency_max (9 = alternate spellings).")
+	lexiconCmd.AddCommand(melodyCmd)
 	rootCmd.AddCommand(lexiconCmd)
 }
 
@@ -31,9 +45,18 @@ var (
 	categoryFlagValue           string
 	englishTranslationFlagValue string
 	englishDefinitionFlagValue  string
+	patternFlagValue            string
 	frequencyMinFlagValue       int
 	frequencyMaxFlagValue       int
 
+	contrastFlagValue            []string
+	minimalFrequencyMaxFlagValue int
+
+	syllablesFlagValue         int
+	matchHeightFlagValue       bool
+	matchNasalityFlagValue     bool
+	rhymeFrequencyMaxFlagValue int
+
 	lexiconCmd = &cobra.Command{
 		Use:   "lexicon",
 		Short: "A CLI to interact with the Sango lexicon",
@@ -58,6 +81,13 @@ var (
 				FrequencyMin:         frequencyMinFlagValue,
 				FrequencyMax:         frequencyMaxFlagValue,
 			}
+			if patternFlagValue != "" {
+				p, err := sse.CompilePattern(patternFlagValue)
+				if err != nil {
+					log.Fatal(err)
+				}
+				f.Pattern = p
+			}
 
 			dictRows := Lookup(LexiconRows(), f)
 			for k, row := range dictRows {
@@ -65,4 +95,67 @@ var (
 			}
 		},
 	}
+
+	minimalPairsCmd = &cobra.Command{
+		Use:   "minimal-pairs",
+		Short: "Write sets of lexicon entries that differ in one feature of one syllable, with their glosses",
+		Args:  cobra.MaximumNArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			var contrasts []Contrast
+			for _, name := range contrastFlagValue {
+				c, ok := ContrastOf(name)
+				if !ok {
+					log.Fatalf("unknown contrast %q: expected pitch, height, nasality, or consonant", name)
+				}
+				contrasts = append(contrasts, c)
+			}
+			rows := Lookup(LexiconRows(), DictRowRegexp{FrequencyMax: minimalFrequencyMaxFlagValue + 1})
+			for k, set := range MinimalSets(rows, contrasts...) {
+				if k > 0 {
+					fmt.Println()
+				}
+				var lemmas []string
+				for _, word := range set.Words {
+					lemmas = append(lemmas, word[0].Lemma)
+				}
+				fmt.Printf("%v of syllable %v: %v\n", set.Contrast, set.Syllable+1, strings.Join(lemmas, " | "))
+				for _, word := range set.Words {
+					for _, row := range word {
+						fmt.Printf("\t%v\t%v\t%v\n", row.Lemma, row.UDPos, row.EnglishTranslation)
+					}
+				}
+			}
+		},
+	}
+
+	rhymesCmd = &cobra.Command{
+		Use:     "rhymes <word>",
+		Short:   "Write the lexicon entries that rhyme with the word (same final vowel and tone), most frequent first",
+		Example: "  sango lexicon rhymes kɔ̂bɛ --syllables 2",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			options := RhymeOptions{Syllables: syllablesFlagValue, MatchHeight: matchHeightFlagValue, MatchNasality: matchNasalityFlagValue}
+			writeRows(rhymeIndex().Rhymes(args[0], options))
+		},
+	}
+
+	melodyCmd = &cobra.Command{
+		Use:     "melody <word>",
+		Short:   "Write the lexicon entries with the tone melody of the word (or of a melody such as HL), most frequent first",
+		Example: "  sango lexicon melody kɔ̂bɛ",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			writeRows(rhymeIndex().Melody(args[0]))
+		},
+	}
 )
+
+func rhymeIndex() *RhymeIndex {
+	return NewRhymeIndex(Lookup(LexiconRows(), DictRowRegexp{FrequencyMax: rhymeFrequencyMaxFlagValue + 1}))
+}
+
+func writeRows(rows DictRows) {
+	for _, row := range rows {
+		fmt.Printf("%v\t%v\t%v\t%v\n", row.Lemma, MelodyOf(row.SSEs()), row.UDPos, row.EnglishTranslation)
+	}
+}
@@ -10,8 +10,11 @@ import (
 	"regexp"
 	"slices"
 	"strings"
+	"unicode"
 	"unicode/utf8"
-	// "golang.org/x/text/unicode/norm"
+
+	"github.com/zokwezo/sango/src/lib/sse"
+	"golang.org/x/text/unicode/norm"
 )
 
 // Sango lexicon in row-major and column-major order, outer joined with compatible affixes.
@@ -21,6 +24,7 @@ func CanonicalFromLemma() map[string]string     { return lexiconRowsAndCols.cano
 func LemmaFromCanonical() map[string]string     { return lexiconRowsAndCols.lemmaFromCanonical }
 func HeightlessFromLemma() map[string]string    { return lexiconRowsAndCols.heightlessFromLemma }
 func TonelessFromHeightless() map[string]string { return lexiconRowsAndCols.tonelessFromHeightless }
+func IndexFromRow() map[DictRow]int             { return lexiconRowsAndCols.indexFromRow } // into LexiconRows
 
 type DictRowsMap = map[string]DictRows
 type DictRows = []DictRow
@@ -98,12 +102,69 @@ type DictRowRegexp struct {
 	EnglishDefinitionRE  *regexp.Regexp
 	FrequencyMin         int
 	FrequencyMax         int
+	Pattern              *sse.Pattern // matches the syllables of Canonical
 }
 
 func Lookup(dictRows DictRows, dictRowRegexp DictRowRegexp) DictRows {
 	return lookupMatchingRows(dictRows, dictRowRegexp)
 }
 
+// Returns the rows with the TonelessKey of word (written with or without tones and
+// vowel height), else those whose Toneless starts with its toneless spelling,
+// else those within a small edit distance of it, in increasing order of Frequency.
+func LookupSango(dictRows DictRows, word string) DictRows {
+	return lookupSango(dictRows, word)
+}
+
+// Returns the rows whose syllables match the pattern (see sse.CompilePattern),
+// e.g. "H L" for two syllables of high then low pitch.
+func LookupPattern(dictRows DictRows, pattern string) (DictRows, error) {
+	p, err := sse.CompilePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return Lookup(dictRows, DictRowRegexp{Pattern: p}), nil
+}
+
+// Returns the rows whose Lemma equals word under mask (e.g. sse.MaskToneless),
+// in increasing order of Frequency.
+func LookupUnder(dictRows DictRows, word string, mask sse.Mask) DictRows {
+	return lookupUnder(dictRows, word, mask)
+}
+
+// Returns the SSEs of the Canonical form of the row.
+func (r DictRow) SSEs() []sse.SSE {
+	return slices.Clone(ssesOf(r))
+}
+
+// Returns the rows whose EnglishTranslation contains the English word,
+// followed by the other rows whose EnglishDefinition contains it.
+func LookupEnglish(dictRows DictRows, word string) DictRows {
+	return lookupEnglish(dictRows, word)
+}
+
+// Returns the key of a Sango word under sse.MaskToneless, e.g. kɔ̂bɛ, kôbe, and KOBE
+// have the same key. Every package compares words without tones by this key.
+func TonelessKey(word string) string {
+	sses, _ := sse.UTF8ToSSEs(word)
+	return sse.WordKeyUnder(sses, sse.MaskToneless)
+}
+
+// Returns the TonelessKey of the row.
+func (r DictRow) TonelessKey() string {
+	if key, ok := lexiconRowsAndCols.tonelessKeyFromCanonical[r.Canonical]; ok {
+		return key
+	}
+	return sse.WordKeyUnder(ssesOf(r), sse.MaskToneless)
+}
+
+// Returns the toneless spelling of a Sango word, e.g. Kɔ̂bɛ -> kobe, as in the Toneless
+// column of the lexicon. Unlike TonelessKey, it is defined for a partially typed word,
+// so it is only used to match prefixes and misspellings.
+func TonelessOf(word string) string {
+	return tonelessOf(word)
+}
+
 //////////////////////////////////////////////////////////////////////////////
 // IMPLEMENTATION
 
@@ -121,20 +182,153 @@ func lookupMatchingRows(in DictRows, f DictRowRegexp) DictRows {
 			(f.UDFeatureRE == nil || f.UDFeatureRE.MatchString(r.UDFeature)) &&
 			(f.CategoryRE == nil || f.CategoryRE.MatchString(r.Category)) &&
 			(f.EnglishTranslationRE == nil || f.EnglishTranslationRE.MatchString(r.EnglishTranslation)) &&
-			(f.EnglishDefinitionRE == nil || f.EnglishDefinitionRE.MatchString(r.EnglishDefinition)) {
+			(f.EnglishDefinitionRE == nil || f.EnglishDefinitionRE.MatchString(r.EnglishDefinition)) &&
+			(f.Pattern == nil || f.Pattern.Match(ssesOf(r))) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Parts of word that are not valid Sango syllables (e.g. a partially typed word)
+// are made toneless by dropping their diacritics and vowel height.
+func tonelessOf(word string) string {
+	sses, _ := sse.UTF8ToSSEs(word)
+	var s strings.Builder
+	for _, x := range sses {
+		x.WriteAsTonelessTo(&s)
+	}
+	var toneless strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s.String())) {
+		switch {
+		case unicode.Is(unicode.Mn, r), r == '-', unicode.IsSpace(r):
+		case r == 'ɛ':
+			toneless.WriteRune('e')
+		case r == 'ɔ':
+			toneless.WriteRune('o')
+		default:
+			toneless.WriteRune(r)
+		}
+	}
+	return toneless.String()
+}
+
+func byFrequency(rows DictRows) DictRows {
+	slices.SortStableFunc(rows, func(a, b DictRow) int { return a.Frequency - b.Frequency })
+	return rows
+}
+
+func lookupSango(in DictRows, word string) DictRows {
+	word = strings.TrimSpace(word)
+	toneless := tonelessOf(word)
+	if toneless == "" {
+		return nil
+	}
+	key := TonelessKey(word)
+	var exact DictRows
+	for _, r := range in {
+		if r.Toneless != "" && r.TonelessKey() == key {
+			exact = append(exact, r)
+		}
+	}
+	if len(exact) > 0 {
+		return byFrequency(exact)
+	}
+
+	// A partially typed word has no syllables to key, e.g. kɔ̂b, so prefixes and
+	// misspellings are matched by toneless spelling.
+	if out := Lookup(in, DictRowRegexp{TonelessRE: regexp.MustCompile(`^` + regexp.QuoteMeta(toneless))}); len(out) > 0 {
+		return byFrequency(out)
+	}
+	maxDistance := 1 + utf8.RuneCountInString(toneless)/5
+	var out DictRows
+	for distance := 1; distance <= maxDistance && len(out) == 0; distance++ {
+		for _, r := range in {
+			if r.Toneless != "" && editDistance(r.Toneless, toneless) == distance {
+				out = append(out, r)
+			}
+		}
+	}
+	return byFrequency(out)
+}
+
+// The SSEs of a row of the lexicon are parsed only once.
+func ssesOf(r DictRow) []sse.SSE {
+	if sses, ok := lexiconRowsAndCols.ssesFromCanonical[r.Canonical]; ok {
+		return sses
+	}
+	sses, _ := sse.CanonicalToSSEs(r.Canonical)
+	return sses
+}
+
+func lookupUnder(in DictRows, word string, mask sse.Mask) DictRows {
+	sses, err := sse.UTF8ToSSEs(strings.TrimSpace(word))
+	if err != nil || len(sses) == 0 {
+		return nil
+	}
+	key := sse.WordKeyUnder(sses, mask)
+	var out DictRows
+	for _, r := range in {
+		if r.Toneless != "" && sse.WordKeyUnder(ssesOf(r), mask) == key {
+			out = append(out, r)
+		}
+	}
+	return byFrequency(out)
+}
+
+func lookupEnglish(in DictRows, word string) DictRows {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil
+	}
+	quoted := regexp.QuoteMeta(strings.ToLower(word))
+	exact := Lookup(in, DictRowRegexp{EnglishTranslationRE: regexp.MustCompile(`(?i)^` + quoted + `$`)})
+	out := byFrequency(exact)
+	for _, r := range byFrequency(Lookup(in, DictRowRegexp{EnglishTranslationRE: regexp.MustCompile(`(?i)(^|[^\pL])` + quoted + `($|[^\pL])`)})) {
+		if !slices.Contains(out, r) {
+			out = append(out, r)
+		}
+	}
+	for _, r := range byFrequency(Lookup(in, DictRowRegexp{EnglishDefinitionRE: regexp.MustCompile(`(?i)\b` + quoted + `\b`)})) {
+		if !slices.Contains(out, r) {
 			out = append(out, r)
 		}
 	}
 	return out
 }
 
+// Levenshtein distance between the runes of a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
 type dictRowsAndCols struct {
-	rows                   DictRows
-	cols                   DictCols
-	canonicalFromLemma     map[string]string
-	lemmaFromCanonical     map[string]string
-	heightlessFromLemma    map[string]string
-	tonelessFromHeightless map[string]string
+	rows                     DictRows
+	cols                     DictCols
+	canonicalFromLemma       map[string]string
+	lemmaFromCanonical       map[string]string
+	heightlessFromLemma      map[string]string
+	tonelessFromHeightless   map[string]string
+	ssesFromCanonical        map[string][]sse.SSE
+	tonelessKeyFromCanonical map[string]string
+	indexFromRow             map[DictRow]int
 }
 
 var lexiconRowsAndCols = func() dictRowsAndCols {
@@ -2414,6 +2608,9 @@ var lexiconRowsAndCols = func() dictRowsAndCols {
 	lemmaFromCanonical := make(map[string]string)
 	heightlessFromLemma := make(map[string]string)
 	tonelessFromHeightless := make(map[string]string)
+	ssesFromCanonical := make(map[string][]sse.SSE)
+	tonelessKeyFromCanonical := make(map[string]string)
+	indexFromRow := make(map[DictRow]int)
 	cols.Bytes = make([]byte, numBytes)
 	cols.Runes = make([]rune, numRunes)
 	endRune := 0
@@ -2423,6 +2620,9 @@ var lexiconRowsAndCols = func() dictRowsAndCols {
 		lemmaFromCanonical[r.Canonical] = r.Lemma
 		heightlessFromLemma[r.Lemma] = r.Heightless
 		tonelessFromHeightless[r.Heightless] = r.Toneless
+		ssesFromCanonical[r.Canonical], _ = sse.CanonicalToSSEs(r.Canonical)
+		tonelessKeyFromCanonical[r.Canonical] = sse.WordKeyUnder(ssesFromCanonical[r.Canonical], sse.MaskToneless)
+		indexFromRow[r] = k
 
 		startByte := endByte
 		endByte += copy(cols.Bytes[startByte:], []byte(r.Toneless))
@@ -2492,12 +2692,15 @@ var lexiconRowsAndCols = func() dictRowsAndCols {
 	}
 
 	return dictRowsAndCols{
-		rows:                   rows,
-		cols:                   cols,
-		canonicalFromLemma:     canonicalFromLemma,
-		lemmaFromCanonical:     lemmaFromCanonical,
-		heightlessFromLemma:    heightlessFromLemma,
-		tonelessFromHeightless: tonelessFromHeightless,
+		rows:                     rows,
+		cols:                     cols,
+		canonicalFromLemma:       canonicalFromLemma,
+		lemmaFromCanonical:       lemmaFromCanonical,
+		heightlessFromLemma:      heightlessFromLemma,
+		tonelessFromHeightless:   tonelessFromHeightless,
+		ssesFromCanonical:        ssesFromCanonical,
+		tonelessKeyFromCanonical: tonelessKeyFromCanonical,
+		indexFromRow:             indexFromRow,
 	}
 }()
 
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
+	"strings"
 	"testing"
 
 	cuckoo "github.com/panmari/cuckoofilter"
+	"github.com/zokwezo/sango/src/lib/sse"
 )
 
 var canonicalRE = regexp.MustCompile(`^([-]?[BDGHKPQVYZbdfghklmnpqrstvwyz][AEIOUaceioux][_:^]){0,5}$`)
@@ -248,3 +251,194 @@ func TestRowsMatchingToneless(t *testing.T) {
 		t.Error("expect: " + expectStr)
 	}
 }
+
+func TestToneless(t *testing.T) {
+	for _, s := range []string{"kɔ̂bɛ", "kôbe", "kobe", "KƆ̂BƐ", "kɔ̂-bɛ"} {
+		if actual := TonelessOf(s); actual != "kobe" {
+			t.Errorf("TonelessOf(%q) = %q", s, actual)
+		}
+		if TonelessKey(s) != TonelessKey("kobe") {
+			t.Errorf("TonelessKey(%q) != TonelessKey(kobe)", s)
+		}
+	}
+	if TonelessKey("kobe") == TonelessKey("koba") {
+		t.Error("TonelessKey(kobe) == TonelessKey(koba)")
+	}
+	// The toneless spelling itself may not parse back into the same syllables,
+	// e.g. inin (in-in), so words are keyed by their lemma or as written.
+	for _, row := range LexiconRows() {
+		if row.Toneless == "" {
+			continue
+		}
+		if TonelessOf(row.Lemma) != row.Toneless {
+			t.Errorf("TonelessOf(%q) = %q, but the lexicon has %q", row.Lemma, TonelessOf(row.Lemma), row.Toneless)
+		}
+		if TonelessKey(row.Lemma) != row.TonelessKey() {
+			t.Errorf("TonelessKey(%q) != TonelessKey of %q", row.Lemma, row.Canonical)
+		}
+	}
+}
+
+func TestLookupSango(t *testing.T) {
+	for _, test := range []struct {
+		word   string
+		expect string
+	}{
+		{"kɔ̂bɛ", "[kɔ̂bɛ]"},
+		{"KOBE", "[kɔ̂bɛ]"},
+		{"ngbangbo-tûk", "[ngbangbo-tukîa]"},
+		{"kobee", "[kɔ̂bɛ]"},
+	} {
+		var lemmas []string
+		for _, row := range LookupSango(LexiconRows(), test.word) {
+			lemmas = append(lemmas, row.Lemma)
+		}
+		if actual := fmt.Sprint(lemmas); actual != test.expect {
+			t.Errorf("LookupSango(%q)\nexpect: %v\nactual: %v", test.word, test.expect, actual)
+		}
+	}
+	if actual := LookupSango(LexiconRows(), " "); len(actual) != 0 {
+		t.Errorf("LookupSango(\" \") = %v", actual)
+	}
+}
+
+func TestLookupEnglish(t *testing.T) {
+	actual := LookupEnglish(LexiconRows(), "Pig")
+	if len(actual) < 3 || actual[0].Lemma != "kɔsɔ" || actual[1].Lemma != "ngûru" || actual[2].Lemma != "mbɛ̈ngɛ̈" {
+		t.Errorf("LookupEnglish(Pig) = %v", actual)
+	}
+	for _, row := range actual {
+		if row.Toneless == "" {
+			t.Errorf("unexpected copyright row")
+		}
+	}
+}
+
+func TestLookupUnder(t *testing.T) {
+	for _, test := range []struct {
+		word   string
+		mask   sse.Mask
+		expect string
+	}{
+		{"dɛ̈", sse.MaskNone, "[dɛ̈ dɛ̈]"},
+		{"dë", sse.MaskHeightless, "[dɛ̈ dɛ̈ dë]"},
+		{"DE", sse.MaskToneless, "[dɛ dɛ̈ de dê dɛ̈ dë]"},
+	} {
+		var lemmas []string
+		for _, row := range LookupUnder(LexiconRows(), test.word, test.mask) {
+			lemmas = append(lemmas, row.Lemma)
+		}
+		if actual := fmt.Sprint(lemmas); actual != test.expect {
+			t.Errorf("LookupUnder(%q, %v)\nexpect: %v\nactual: %v", test.word, test.mask, test.expect, actual)
+		}
+	}
+}
+
+func TestLookupPattern(t *testing.T) {
+	actual, err := LookupPattern(LexiconRows(), "kɔ̂ *")
+	if err != nil || len(actual) == 0 {
+		t.Fatalf("LookupPattern: %v, %v", actual, err)
+	}
+	for _, row := range actual {
+		if !strings.HasPrefix(row.Canonical, "kc^") {
+			t.Errorf("LookupPattern(kɔ̂ *) returned %v", row)
+		}
+	}
+	if _, err := LookupPattern(LexiconRows(), "kɔ̂ q"); err == nil {
+		t.Errorf("LookupPattern(kɔ̂ q): expected an error")
+	}
+}
+
+func TestMinimalSets(t *testing.T) {
+	lemmasOf := func(set MinimalSet) string {
+		var lemmas []string
+		for _, word := range set.Words {
+			lemmas = append(lemmas, fmt.Sprintf("%v %v", len(word), word[0].Lemma))
+		}
+		return fmt.Sprintf("%v %v %v", set.Contrast, set.Syllable, lemmas)
+	}
+	expect := []string{
+		"pitch 0 [1 ba 2 bä 1 bâ]",
+		"pitch 0 [1 de 1 dë 1 dê]",
+		"height 0 [1 dɛ 1 de]",
+		"nasality 0 [4 sô 2 sôn]",
+		"consonant 0 [1 bata 1 mbata]",
+	}
+	var actual []string
+	for _, set := range MinimalSets(Lookup(LexiconRows(), DictRowRegexp{FrequencyMax: 9})) {
+		if s := lemmasOf(set); slices.Contains(expect, s) {
+			actual = append(actual, s)
+		}
+	}
+	if !slices.Equal(actual, expect) {
+		t.Errorf("MinimalSets\nexpect: %q\nactual: %q", expect, actual)
+	}
+
+	// Syllables of unknown height or pitch contrast with no other.
+	var rows DictRows
+	for _, canonical := range []string{"dx_", "de_", "dX_", "da", "da_", "dA_", "kX-bx_", "ke-bx_", "kx-bx_", "kX-be_"} {
+		rows = append(rows, DictRow{Lemma: canonical, Toneless: canonical, Canonical: canonical})
+	}
+	actual = nil
+	for _, set := range MinimalSets(rows) {
+		var lemmas []string
+		for _, word := range set.Words {
+			lemmas = append(lemmas, word[0].Lemma)
+		}
+		actual = append(actual, fmt.Sprintf("%v %v %v", set.Contrast, set.Syllable, lemmas))
+	}
+	expect = []string{
+		"height 0 [dx_ de_]",
+		"height 1 [kX-bx_ kX-be_]",
+		"height 0 [kx-bx_ ke-bx_]",
+		"nasality 0 [da_ dA_]",
+	}
+	if !slices.Equal(actual, expect) {
+		t.Errorf("MinimalSets of unknown height or pitch\nexpect: %q\nactual: %q", expect, actual)
+	}
+	if c, ok := ContrastOf("nasality"); !ok || c != ContrastNasality || c.String() != "nasality" {
+		t.Errorf("ContrastOf(nasality) = %v, %v", c, ok)
+	}
+}
+
+func TestRhymes(t *testing.T) {
+	index := NewRhymeIndex(LexiconRows())
+	lemmasOf := func(rows DictRows) []string {
+		var lemmas []string
+		for _, row := range rows {
+			lemmas = append(lemmas, row.Lemma)
+		}
+		return lemmas
+	}
+	for _, test := range []struct {
+		word    string
+		options RhymeOptions
+		expect  string // one of the rhymes
+		reject  string // not one of the rhymes
+	}{
+		{"kɔ̂bɛ", RhymeOptions{}, "âdɛ", "kɔ̂bɛ"},
+		{"kɔ̂bɛ", RhymeOptions{}, "ge", "bâ"},
+		{"kɔ̂bɛ", RhymeOptions{MatchHeight: true}, "âdɛ", "ge"},
+		{"mbâkôro", RhymeOptions{Syllables: 2}, "yɔ̂rɔ", "kɔ̂bɛ"},
+		{"mbâkôro", RhymeOptions{Syllables: 2, MatchHeight: true}, "kôro", "yɔ̂rɔ"},
+	} {
+		lemmas := lemmasOf(index.Rhymes(test.word, test.options))
+		if !slices.Contains(lemmas, test.expect) || slices.Contains(lemmas, test.reject) {
+			t.Errorf("Rhymes(%q, %+v) = %v", test.word, test.options, lemmas)
+		}
+	}
+	rows := index.Rhymes("sô", RhymeOptions{})
+	if !slices.IsSortedFunc(rows, func(a, b DictRow) int { return a.Frequency - b.Frequency }) {
+		t.Errorf("rhymes not in order of Frequency")
+	}
+
+	lemmas := lemmasOf(index.Melody("kɔ̂bɛ"))
+	if !slices.Contains(lemmas, "âdɛ") || slices.Contains(lemmas, "kɔ̂bɛ") || slices.Contains(lemmas, "ge") {
+		t.Errorf("Melody(kɔ̂bɛ) = %v", lemmas)
+	}
+	for _, row := range index.Melody("HML") {
+		if melody := MelodyOf(row.SSEs()); melody != "HML" {
+			t.Errorf("Melody(HML) returned %v of melody %v", row.Lemma, melody)
+		}
+	}
+}
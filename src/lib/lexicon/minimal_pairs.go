@@ -0,0 +1,205 @@
+// Minimal pairs and homophones of the Sango lexicon.
+//
+// A minimal set groups the entries whose lemmas differ from each other in just
+// one feature of one syllable: its pitch, its vowel height, its vowel nasality,
+// or its consonant, e.g. ba, bâ, and bä differ only in the pitch of syllable 1.
+// Lemmas are compared as SSE syllables, ignoring case, spaces, and hyphens.
+// Entries of the same lemma (homophones) are listed together.
+
+package lexicon
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/zokwezo/sango/src/lib/sse"
+)
+
+type Contrast int
+
+const (
+	ContrastPitch Contrast = iota
+	ContrastHeight
+	ContrastNasality
+	ContrastConsonant
+)
+
+var Contrasts = []Contrast{ContrastPitch, ContrastHeight, ContrastNasality, ContrastConsonant}
+
+func (c Contrast) String() string {
+	return contrastNames[c]
+}
+
+// Returns the contrast with this name (pitch, height, nasality, or consonant).
+func ContrastOf(name string) (Contrast, bool) {
+	for c, n := range contrastNames {
+		if n == name {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+type MinimalSet struct {
+	Contrast Contrast
+	Syllable int        // 0-based index of the syllable that differs
+	Words    []DictRows // the entries of each lemma, in Sango collation order
+}
+
+// Returns the minimal sets of the rows for each contrast (all of them if none are given),
+// ordered by contrast, then by the collation of their first lemma.
+func MinimalSets(dictRows DictRows, contrasts ...Contrast) []MinimalSet {
+	if len(contrasts) == 0 {
+		contrasts = Contrasts
+	}
+	return minimalSets(dictRows, contrasts)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+var contrastNames = map[Contrast]string{
+	ContrastPitch:     "pitch",
+	ContrastHeight:    "height",
+	ContrastNasality:  "nasality",
+	ContrastConsonant: "consonant",
+}
+
+// The vowel of unknown height of each vowel with a height contrast. A vowel of
+// unknown height (X or C) is not itself a key, since it may be either height.
+var heightlessVowels = map[sse.VowelCode]sse.VowelCode{
+	sse.VowelCode_e: sse.VowelCode_X,
+	sse.VowelCode_x: sse.VowelCode_X,
+	sse.VowelCode_o: sse.VowelCode_C,
+	sse.VowelCode_c: sse.VowelCode_C,
+}
+
+// The oral vowel of each vowel with a nasality contrast.
+var oralVowels = map[sse.VowelCode]sse.VowelCode{
+	sse.VowelCode_a: sse.VowelCode_a,
+	sse.VowelCode_A: sse.VowelCode_a,
+	sse.VowelCode_e: sse.VowelCode_e,
+	sse.VowelCode_E: sse.VowelCode_e,
+	sse.VowelCode_i: sse.VowelCode_i,
+	sse.VowelCode_I: sse.VowelCode_i,
+	sse.VowelCode_o: sse.VowelCode_o,
+	sse.VowelCode_O: sse.VowelCode_o,
+	sse.VowelCode_u: sse.VowelCode_u,
+	sse.VowelCode_U: sse.VowelCode_u,
+}
+
+// Words in the same minimal set have the same key, in which the contrasting
+// feature of the syllable is replaced by a common value.
+type minimalKey struct {
+	contrast Contrast
+	syllable int
+	word     string
+}
+
+const minimalMask = sse.IgnoreShift | sse.IgnorePrefix | sse.IgnoreInfix
+
+// Encodes the consonant, vowel, and pitch of each syllable (under minimalMask).
+func syllablesKey(syllables []sse.Syllable) string {
+	var key strings.Builder
+	for _, s := range syllables {
+		key.WriteString(s.Key())
+	}
+	return key.String()
+}
+
+// Returns the syllable with its contrasting feature replaced by a common value,
+// or false if the syllable cannot contrast in that feature, e.g. its pitch or
+// vowel height is unknown.
+func neutralized(s sse.Syllable, c Contrast) (sse.Syllable, bool) {
+	switch c {
+	case ContrastPitch:
+		if s.Pitch == sse.PitchCode_Unknown {
+			return s, false
+		}
+		s.Pitch = sse.PitchCode_Unknown
+	case ContrastHeight:
+		v, ok := heightlessVowels[s.Vowel]
+		if !ok {
+			return s, false
+		}
+		s.Vowel = v
+	case ContrastNasality:
+		v, ok := oralVowels[s.Vowel]
+		if !ok {
+			return s, false
+		}
+		s.Vowel = v
+	case ContrastConsonant:
+		s.Consonant = sse.ConsonantCode_None
+	}
+	return s, true
+}
+
+func minimalSets(in DictRows, contrasts []Contrast) []MinimalSet {
+	// Group the rows by lemma.
+	var words []string
+	rowsFromWord := map[string]DictRows{}
+	syllablesFromWord := map[string][]sse.Syllable{}
+	for _, r := range in {
+		if r.Toneless == "" {
+			continue
+		}
+		var syllables []sse.Syllable
+		for _, x := range ssesOf(r) {
+			syllables = append(syllables, x.KeyUnder(minimalMask).Syllables()...)
+		}
+		word := sse.WordKeyUnder(ssesOf(r), minimalMask)
+		if _, ok := rowsFromWord[word]; !ok {
+			words = append(words, word)
+			syllablesFromWord[word] = syllables
+		}
+		rowsFromWord[word] = append(rowsFromWord[word], r)
+	}
+
+	// Group the lemmas by minimal key.
+	var keys []minimalKey
+	wordsFromKey := map[minimalKey][]string{}
+	for _, word := range words {
+		syllables := syllablesFromWord[word]
+		for _, c := range contrasts {
+			for k := range syllables {
+				s, ok := neutralized(syllables[k], c)
+				if !ok {
+					continue
+				}
+				neutral := slices.Clone(syllables)
+				neutral[k] = s
+				key := minimalKey{contrast: c, syllable: k, word: syllablesKey(neutral)}
+				if _, ok := wordsFromKey[key]; !ok {
+					keys = append(keys, key)
+				}
+				wordsFromKey[key] = append(wordsFromKey[key], word)
+			}
+		}
+	}
+
+	var sets []MinimalSet
+	for _, key := range keys {
+		if len(wordsFromKey[key]) < 2 {
+			continue
+		}
+		set := MinimalSet{Contrast: key.contrast, Syllable: key.syllable}
+		for _, word := range wordsFromKey[key] {
+			set.Words = append(set.Words, rowsFromWord[word])
+		}
+		slices.SortStableFunc(set.Words, func(a, b DictRows) int {
+			return sse.CompareWordsUnder(ssesOf(a[0]), ssesOf(b[0]), minimalMask)
+		})
+		sets = append(sets, set)
+	}
+	slices.SortStableFunc(sets, func(a, b MinimalSet) int {
+		if a.Contrast != b.Contrast {
+			return int(a.Contrast) - int(b.Contrast)
+		}
+		if c := sse.CompareWordsUnder(ssesOf(a.Words[0][0]), ssesOf(b.Words[0][0]), minimalMask); c != 0 {
+			return c
+		}
+		return a.Syllable - b.Syllable
+	})
+	return sets
+}
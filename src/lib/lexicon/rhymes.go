@@ -0,0 +1,199 @@
+// Rhymes and tone melodies of the Sango lexicon.
+//
+// Two words rhyme if their final vowels have the same pitch, e.g. kɔ̂bɛ and
+// âdɛ, or (over more syllables) if they also end with the same syllables from
+// the vowel of the first, e.g. kôro and yɔ̂rɔ. Vowel height and nasality are
+// ignored unless asked for, since e and ɛ (o and ɔ, a and an) rhyme in song.
+// The tone melody of a word is the pitch of each syllable, e.g. HL for kɔ̂bɛ.
+//
+// A RhymeIndex sorts the words of the lexicon by their syllables in reverse
+// order (from the last vowel back), so that the words ending the same way are
+// adjacent, and groups them by tone melody.
+
+package lexicon
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/zokwezo/sango/src/lib/sse"
+)
+
+type RhymeOptions struct {
+	Syllables     int  // number of final syllables that must rhyme (at least 1)
+	MatchHeight   bool // whether e and ɛ (o and ɔ) do not rhyme
+	MatchNasality bool // whether nasal and oral vowels do not rhyme
+}
+
+type RhymeIndex struct {
+	rows       DictRows
+	melodies   map[string]DictRows
+	mu         sync.Mutex
+	byReversed map[sse.Mask][]reversedRow // rows sorted by reversed key, built on first use
+}
+
+// Indexes the rows with Sango syllables.
+func NewRhymeIndex(dictRows DictRows) *RhymeIndex {
+	return newRhymeIndex(dictRows)
+}
+
+// Returns the rows (other than word itself) that rhyme with word, in increasing order of Frequency.
+func (x *RhymeIndex) Rhymes(word string, options RhymeOptions) DictRows {
+	return x.rhymes(word, options)
+}
+
+// Returns the rows (other than word itself) with the tone melody of word, in
+// increasing order of Frequency. Word may also be a melody, e.g. HL.
+func (x *RhymeIndex) Melody(word string) DictRows {
+	return x.melody(word)
+}
+
+// Returns the tone melody of a word, with one of H (high), M (mid), L (low), or
+// U (unknown) per syllable, or "" if it has no Sango syllables.
+func MelodyOf(sses []sse.SSE) string {
+	return melodyOf(sses)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+func melodyOf(sses []sse.SSE) string {
+	var s strings.Builder
+	for _, x := range sses {
+		s.WriteString(x.Melody())
+	}
+	return s.String()
+}
+
+// Words differing only in case and spaces have the same melody and are the same word.
+const melodyMask = sse.IgnoreShift | sse.IgnorePrefix
+
+func isMelody(word string) bool {
+	return word != "" && strings.Trim(word, "HMLU") == ""
+}
+
+// The mask of a rhyme ignores case, spaces, and hyphens, and unless asked for,
+// vowel height and nasality.
+func rhymeMask(options RhymeOptions) sse.Mask {
+	mask := sse.IgnoreShift | sse.IgnorePrefix | sse.IgnoreInfix
+	if !options.MatchHeight {
+		mask |= sse.IgnoreHeight
+	}
+	if !options.MatchNasality {
+		mask |= sse.IgnoreNasality
+	}
+	return mask
+}
+
+// Returns the syllables of a word under mask, from the last to the first.
+func reversedSyllables(sses []sse.SSE, mask sse.Mask) []sse.Syllable {
+	var syllables []sse.Syllable
+	for _, x := range sses {
+		syllables = append(syllables, x.KeyUnder(mask).Syllables()...)
+	}
+	slices.Reverse(syllables)
+	return syllables
+}
+
+// Encodes the reversed syllables of a word from its last vowel back: the vowel
+// and pitch of each syllable, then the consonant that precedes them.
+func reversedKey(syllables []sse.Syllable) string {
+	var key strings.Builder
+	for _, s := range syllables {
+		key.WriteString(sse.Syllable{Vowel: s.Vowel, Pitch: s.Pitch}.Key())
+		key.WriteString(sse.Syllable{Consonant: s.Consonant}.Key())
+	}
+	return key.String()
+}
+
+// The key of the final n syllables, but for the consonant of the nth.
+func rhymeKey(syllables []sse.Syllable, n int) string {
+	n = min(n, len(syllables))
+	last := syllables[n-1]
+	return reversedKey(syllables[:n-1]) + sse.Syllable{Vowel: last.Vowel, Pitch: last.Pitch}.Key()
+}
+
+type reversedRow struct {
+	key string
+	row DictRow
+}
+
+func newRhymeIndex(in DictRows) *RhymeIndex {
+	x := &RhymeIndex{melodies: map[string]DictRows{}, byReversed: map[sse.Mask][]reversedRow{}}
+	for _, r := range in {
+		if r.Toneless == "" {
+			continue
+		}
+		melody := melodyOf(ssesOf(r))
+		if melody == "" {
+			continue
+		}
+		x.rows = append(x.rows, r)
+		x.melodies[melody] = append(x.melodies[melody], r)
+	}
+	for melody, rows := range x.melodies {
+		x.melodies[melody] = byFrequency(rows)
+	}
+	return x
+}
+
+func (x *RhymeIndex) reversedRows(mask sse.Mask) []reversedRow {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if rows, ok := x.byReversed[mask]; ok {
+		return rows
+	}
+	var rows []reversedRow
+	for _, r := range x.rows {
+		rows = append(rows, reversedRow{reversedKey(reversedSyllables(ssesOf(r), mask)), r})
+	}
+	slices.SortStableFunc(rows, func(a, b reversedRow) int { return strings.Compare(a.key, b.key) })
+	x.byReversed[mask] = rows
+	return rows
+}
+
+func (x *RhymeIndex) rhymes(word string, options RhymeOptions) DictRows {
+	sses, err := sse.UTF8ToSSEs(strings.TrimSpace(word))
+	mask := rhymeMask(options)
+	syllables := reversedSyllables(sses, mask)
+	if err != nil || len(syllables) == 0 {
+		return nil
+	}
+	self := sse.WordKeyUnder(sses, mask)
+	prefix := rhymeKey(syllables, max(1, options.Syllables))
+
+	// The rows whose reversed key starts with the prefix are adjacent.
+	rows := x.reversedRows(mask)
+	begin := sort.Search(len(rows), func(k int) bool { return rows[k].key >= prefix })
+	var out DictRows
+	for _, r := range rows[begin:] {
+		if !strings.HasPrefix(r.key, prefix) {
+			break
+		}
+		if sse.WordKeyUnder(ssesOf(r.row), mask) != self {
+			out = append(out, r.row)
+		}
+	}
+	return byFrequency(out)
+}
+
+func (x *RhymeIndex) melody(word string) DictRows {
+	word = strings.TrimSpace(word)
+	melody, self := word, ""
+	if !isMelody(word) {
+		sses, err := sse.UTF8ToSSEs(word)
+		if err != nil {
+			return nil
+		}
+		melody, self = melodyOf(sses), sse.WordKeyUnder(sses, melodyMask)
+	}
+	var out DictRows
+	for _, r := range x.melodies[melody] {
+		if sse.WordKeyUnder(ssesOf(r), melodyMask) != self {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package numbers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func Init(rootCmd *cobra.Command) {
+	rootCmd.AddCommand(numbersCmd)
+	numbersCmd.AddCommand(spellCmd)
+	numbersCmd.AddCommand(parseCmd)
+	numbersCmd.AddCommand(normalizeCmd)
+}
+
+var (
+	numbersCmd = &cobra.Command{
+		Use:   "numbers",
+		Short: "A CLI to convert numbers between digits and Sango words",
+	}
+
+	spellCmd = &cobra.Command{
+		Use:     "spell <number>...",
+		Short:   "Spell each number (e.g. 25, 1er, 3,25, 18/10/2026, or \"1000 F CFA\") in Sango",
+		Example: "  sango numbers spell 1998 \"500 F CFA\"",
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, arg := range args {
+				n, err := ParseDigits(arg)
+				if err != nil {
+					log.Fatal(err)
+				}
+				spelled, err := Spell(n)
+				if err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(spelled)
+			}
+		},
+	}
+
+	parseCmd = &cobra.Command{
+		Use:     "parse <phrase>...",
+		Short:   "Write each Sango number phrase, with or without tones, in digits",
+		Example: "  sango numbers parse \"bale-use na oku\"",
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, arg := range args {
+				n, err := Parse(arg)
+				if err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(n)
+			}
+		},
+	}
+
+	normalizeCmd = &cobra.Command{
+		Use:   "normalize",
+		Short: "Read text from stdin, spell out its numbers in Sango, then write to stdout",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			b, err := io.ReadAll(bufio.NewReader(os.Stdin))
+			if err != nil {
+				log.Fatal(err)
+			}
+			if _, err := os.Stdout.WriteString(Normalize(string(b))); err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+)
@@ -0,0 +1,653 @@
+// Sango Number Words
+//
+// Spells numbers in tone-marked Sango, using the numerals of the lexicon:
+// ɔ̂kɔ (1) through gümbâyä (9), balë (10), ngbangbo (100), sâki (1000),
+// kûtu (million), ngbundangbu (billion), and pärä (0).
+//
+// Tens are hyphenated with their multiplier (balë-ûse = 20), and a power of ten is
+// followed by its multiplier unless it is one (ngbangbo otâ = 300, sâki = 1000).
+// The terms of a number, from largest to smallest, are joined by na (and),
+// e.g. 1998 = sâki na ngbangbo gümbâyä na balë-gümbâyä na meambe.
+// A multiplier of several words is hyphenated into one, so that its terms are not
+// those of the number: 25000 = sâki balë-ûse-na-ɔkü, but 20005 = sâki balë-ûse na ɔkü.
+//
+// Ordinals are kɔ̂zɔ (first) and otherwise the cardinal used as an adjective.
+// Decimals count tenths, hundredths, or thousandths with the fraction prefixes
+// sûî, zɛgbɛ, and yakɛ̂rɛ̂, e.g. 3,25 = otâ na zɛgbɛ balë-ûse na ɔkü.
+// Dates name the day, month, and year: lâ D tî <month> tî ngû Y.
+// Amounts in francs CFA are counted in pâta, the 5-franc coin.
+//
+// Parse reads these phrases back, with or without tones.
+
+package numbers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/zokwezo/sango/src/lib/lexicon"
+)
+
+type Kind int
+
+const (
+	KindCardinal Kind = iota // 25
+	KindOrdinal              // 2e
+	KindDecimal              // 3,25
+	KindDate                 // 18/10/2026
+	KindCFA                  // 1000 F CFA
+)
+
+type Number struct {
+	Kind Kind
+	Int  int64     // the cardinal, ordinal, whole part of a decimal, or amount in francs CFA
+	Frac string    // the digits of a decimal after its comma
+	Date time.Time // the date (at midnight UTC)
+}
+
+// The largest number that Cardinal can spell.
+const MaxCardinal = 999_999_999_999
+
+// Returns n spelled in Sango, e.g. 25 -> balë-ûse na ɔkü.
+func Cardinal(n int64) (string, error) {
+	return cardinal(n)
+}
+
+// Returns the ordinal n spelled in Sango, e.g. 1 -> kɔ̂zɔ, 2 -> ûse.
+func Ordinal(n int64) (string, error) {
+	return ordinal(n)
+}
+
+// Returns the decimal whole,frac spelled in Sango, e.g. (3, "25") -> otâ na zɛgbɛ balë-ûse na ɔkü.
+// frac has 1 to 3 digits.
+func Decimal(whole int64, frac string) (string, error) {
+	return decimal(whole, frac)
+}
+
+// Returns the date spelled in Sango, e.g. lâ balë na meambe tî ngbɛrɛrɛ tî ngû sâki ûse na balë-ûse na omɛnë.
+func Date(t time.Time) (string, error) {
+	return date(t)
+}
+
+// Returns an amount in francs CFA spelled in Sango, e.g. 1000 -> pâta ngbangbo ûse.
+// The amount must be a multiple of 5 francs.
+func CFA(francs int64) (string, error) {
+	return cfa(francs)
+}
+
+// Returns n spelled in Sango.
+func Spell(n Number) (string, error) {
+	return spell(n)
+}
+
+// Returns the number spelled by a Sango phrase, written with or without tones.
+func Parse(phrase string) (Number, error) {
+	return parse(phrase)
+}
+
+// Returns the number written in digits, in one of the forms written by Number.String.
+// Thousands may also be grouped with a point or a space (e.g. 10.000 or 10 000),
+// a decimal written with a point (e.g. 3.25), and a year with 2 digits (e.g. 1/1/26).
+func ParseDigits(s string) (Number, error) {
+	return parseDigits(s)
+}
+
+// Returns n written in digits, in the usual French style of Central Africa,
+// e.g. 25, 1er, 2e, 3,25, 18/10/2026, or 1000 F CFA.
+func (n Number) String() string {
+	return n.string()
+}
+
+// Returns text with each number written in digits spelled out in Sango.
+// Numbers that cannot be spelled (e.g. too large) are left as they are.
+func Normalize(text string) string {
+	return normalize(text)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+// Returns the most frequent lemma of the lexicon for each of the English translations.
+func mostFrequent(keep func(row lexicon.DictRow) bool, translations ...string) map[string]string {
+	m := map[string]string{}
+	frequency := map[string]int{}
+	for _, row := range lexicon.LexiconRows() {
+		if !slices.Contains(translations, row.EnglishTranslation) || !keep(row) || row.Frequency == 9 {
+			continue
+		}
+		if f, ok := frequency[row.EnglishTranslation]; !ok || row.Frequency < f {
+			m[row.EnglishTranslation] = row.Lemma
+			frequency[row.EnglishTranslation] = row.Frequency
+		}
+	}
+	return m
+}
+
+var digitNames = [10]string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine"}
+
+var monthNames = [12]string{"January", "February", "March", "April", "May", "June",
+	"July", "August", "September", "October", "November", "December"}
+
+// Fraction prefixes by number of decimal digits.
+var fractions = [4]string{"", "deca-", "centi-", "milli-"}
+
+// Numerals by English translation, e.g. numerals()["two"] = "ûse".
+var numerals = sync.OnceValue(func() map[string]string {
+	translations := slices.Concat(digitNames[:], []string{"ten", "hundred", "thousand", "million", "billion"}, fractions[1:])
+	return mostFrequent(func(row lexicon.DictRow) bool {
+		return row.Category == "NUM" && (row.UDPos == "NUM" || row.EnglishTranslation == "zero")
+	}, translations...)
+})
+
+// Other words of numbers by English translation, e.g. words()["first"] = "kɔ̂zɔ".
+var words = sync.OnceValue(func() map[string]string {
+	translations := slices.Concat(monthNames[:], []string{"first", "day-or-daytime", "of-or-to", "water-or-year", "penny"})
+	return mostFrequent(func(row lexicon.DictRow) bool { return true }, translations...)
+})
+
+var scales = []struct {
+	value int64
+	name  string
+}{
+	{1_000_000_000, "billion"},
+	{1_000_000, "million"},
+	{1_000, "thousand"},
+	{100, "hundred"},
+}
+
+func numeral(english string) string {
+	return lookup(numerals(), english)
+}
+
+func word(english string) string {
+	return lookup(words(), english)
+}
+
+func lookup(m map[string]string, english string) string {
+	w, ok := m[english]
+	if !ok {
+		panic(fmt.Sprintf("lexicon has no word for %q", english))
+	}
+	return w
+}
+
+func cardinal(n int64) (string, error) {
+	if n < 0 || n > MaxCardinal {
+		return "", fmt.Errorf("cannot spell %v: expected 0 to %v", n, MaxCardinal)
+	}
+	if n == 0 {
+		return numeral("zero"), nil
+	}
+	return strings.Join(terms(n), " na "), nil
+}
+
+// Returns the terms of 0 < n < 1000 * billion, from largest to smallest.
+func terms(n int64) []string {
+	var tt []string
+	for _, scale := range scales {
+		if q := n / scale.value; q > 0 {
+			term := numeral(scale.name)
+			if q > 1 {
+				term += " " + strings.ReplaceAll(strings.Join(terms(q), " na "), " ", "-")
+			}
+			tt = append(tt, term)
+			n %= scale.value
+		}
+	}
+	if q := n / 10; q > 0 {
+		term := numeral("ten")
+		if q > 1 {
+			term += "-" + numeral(digitNames[q])
+		}
+		tt = append(tt, term)
+		n %= 10
+	}
+	if n > 0 {
+		tt = append(tt, numeral(digitNames[n]))
+	}
+	return tt
+}
+
+func ordinal(n int64) (string, error) {
+	switch {
+	case n < 1:
+		return "", fmt.Errorf("cannot spell ordinal %v: expected 1 or more", n)
+	case n == 1:
+		return word("first"), nil
+	}
+	return cardinal(n)
+}
+
+func decimal(whole int64, frac string) (string, error) {
+	if len(frac) < 1 || len(frac) >= len(fractions) || strings.Trim(frac, "0123456789") != "" {
+		return "", fmt.Errorf("cannot spell decimal %v,%v: expected 1 to %v digits after the comma", whole, frac, len(fractions)-1)
+	}
+	w, err := cardinal(whole)
+	if err != nil {
+		return "", err
+	}
+	f, _ := strconv.ParseInt(frac, 10, 64)
+	s, _ := cardinal(f)
+	return w + " na " + numeral(fractions[len(frac)]) + " " + s, nil
+}
+
+func date(t time.Time) (string, error) {
+	year, err := cardinal(int64(t.Year()))
+	if err != nil || t.Year() == 0 {
+		return "", fmt.Errorf("cannot spell date %v: expected a year from 1 to %v", t.Format(time.DateOnly), MaxCardinal)
+	}
+	day, _ := cardinal(int64(t.Day()))
+	of := word("of-or-to")
+	return fmt.Sprintf("%v %v %v %v %v %v %v", word("day-or-daytime"), day, of, word(t.Month().String()), of, word("water-or-year"), year), nil
+}
+
+func cfa(francs int64) (string, error) {
+	if francs%5 != 0 {
+		return "", fmt.Errorf("cannot spell %v F CFA: expected a multiple of 5 francs", francs)
+	}
+	n, err := cardinal(francs / 5)
+	if err != nil {
+		return "", err
+	}
+	return word("penny") + " " + n, nil
+}
+
+func spell(n Number) (string, error) {
+	switch n.Kind {
+	case KindCardinal:
+		return cardinal(n.Int)
+	case KindOrdinal:
+		return ordinal(n.Int)
+	case KindDecimal:
+		return decimal(n.Int, n.Frac)
+	case KindDate:
+		return date(n.Date)
+	case KindCFA:
+		return cfa(n.Int)
+	}
+	return "", fmt.Errorf("cannot spell number of kind %v", n.Kind)
+}
+
+// Values of the numerals by lexicon.TonelessKey, e.g. of ûse -> 2, of sâki -> 1000.
+var values = sync.OnceValue(func() map[string]int64 {
+	m := map[string]int64{lexicon.TonelessKey(numeral("ten")): 10}
+	for d, name := range digitNames {
+		m[lexicon.TonelessKey(numeral(name))] = int64(d)
+	}
+	for _, scale := range scales {
+		m[lexicon.TonelessKey(numeral(scale.name))] = scale.value
+	}
+	return m
+})
+
+// Numbers of decimal digits of the fraction prefixes by lexicon.TonelessKey, e.g. of zɛgbɛ -> 2.
+var fractionDigits = sync.OnceValue(func() map[string]int {
+	m := map[string]int{}
+	for k, name := range fractions[1:] {
+		m[lexicon.TonelessKey(numeral(name))] = k + 1
+	}
+	return m
+})
+
+// Months by lexicon.TonelessKey of all their names in the lexicon, e.g. of ngbɛrɛrɛ -> October.
+var months = sync.OnceValue(func() map[string]time.Month {
+	m := map[string]time.Month{}
+	for _, row := range lexicon.LexiconRows() {
+		if k := slices.Index(monthNames[:], row.EnglishTranslation); k >= 0 && row.Category == "WHEN" {
+			m[row.TonelessKey()] = time.Month(k + 1)
+		}
+	}
+	return m
+})
+
+type parser struct {
+	words []string // lexicon.TonelessKeys, with each hyphen as a word of its own
+	i     int
+}
+
+func newParser(phrase string) *parser {
+	p := &parser{}
+	for _, field := range strings.Fields(phrase) {
+		for k, part := range strings.Split(field, "-") {
+			if k > 0 {
+				p.words = append(p.words, "-")
+			}
+			p.words = append(p.words, lexicon.TonelessKey(part))
+		}
+	}
+	return p
+}
+
+func (p *parser) peek() string {
+	if p.i < len(p.words) {
+		return p.words[p.i]
+	}
+	return ""
+}
+
+// Consumes the next word if it is w, or if its lexicon.TonelessKey is that of w.
+func (p *parser) accept(w string) bool {
+	if p.peek() != "" && (p.peek() == w || p.peek() == lexicon.TonelessKey(w)) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(w string) error {
+	if !p.accept(w) {
+		return p.errorf("expected %v", w)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, a ...any) error {
+	at := "at end"
+	if p.i < len(p.words) {
+		at = fmt.Sprintf("at %q", p.words[p.i])
+	}
+	return fmt.Errorf(format+" "+at, a...)
+}
+
+// Parses pärä or a cardinal.
+func (p *parser) number() (int64, error) {
+	if p.accept(numeral("zero")) {
+		return 0, nil
+	}
+	if n, ok := p.cardinal(math.MaxInt64, false); ok {
+		return n, nil
+	}
+	return 0, p.errorf("expected a number")
+}
+
+// Parses a cardinal whose terms all have a place value below the given one.
+// The words of a hyphenated cardinal (a multiplier) are joined by hyphens.
+func (p *parser) cardinal(below int64, hyphenated bool) (int64, bool) {
+	value, place, ok := p.term(below, hyphenated)
+	if !ok {
+		return 0, false
+	}
+	total := value
+	for {
+		start := p.i
+		if !p.acceptJoin(hyphenated) {
+			p.i = start
+			break
+		}
+		if value, place, ok = p.term(place, hyphenated); !ok {
+			p.i = start
+			break
+		}
+		total += value
+	}
+	return total, true
+}
+
+// Consumes the na joining two terms, or the -na- joining two terms of a hyphenated cardinal.
+func (p *parser) acceptJoin(hyphenated bool) bool {
+	if hyphenated {
+		return p.accept("-") && p.accept("na") && p.accept("-")
+	}
+	return p.accept("na")
+}
+
+// Parses a digit, a ten, or a power of ten and its multiplier, whose place value is below the given one.
+func (p *parser) term(below int64, hyphenated bool) (value, place int64, ok bool) {
+	v, isNumeral := values()[p.peek()]
+	switch {
+	case !isNumeral || v == 0:
+		return 0, 0, false
+	case v < 10 && below > 1:
+		p.i++
+		return v, 1, true
+	case v == 10 && below > 10:
+		p.i++
+		if p.accept("-") {
+			if d, ok := values()[p.peek()]; ok && 2 <= d && d <= 9 {
+				p.i++
+				return 10 * d, 10, true
+			}
+			p.i-- // the hyphen joins the next term of a multiplier
+		}
+		return 10, 10, true
+	case v >= 100 && below > v:
+		p.i++
+		start := p.i
+		multiplier, ok := int64(1), false
+		if !hyphenated || p.accept("-") {
+			multiplier, ok = p.cardinal(v, true)
+		}
+		if !ok {
+			p.i = start
+			multiplier = 1
+		}
+		return multiplier * v, v, true
+	}
+	return 0, 0, false
+}
+
+func parse(phrase string) (Number, error) {
+	p := newParser(phrase)
+	var n Number
+	var err error
+	switch {
+	case p.accept(word("first")):
+		n = Number{Kind: KindOrdinal, Int: 1}
+	case p.accept(word("penny")):
+		n.Kind = KindCFA
+		n.Int, err = p.number()
+		n.Int *= 5
+	case p.accept(word("day-or-daytime")):
+		n.Kind = KindDate
+		n.Date, err = p.date()
+	default:
+		n.Kind = KindCardinal
+		if n.Int, err = p.number(); err == nil && p.accept("na") {
+			n.Kind = KindDecimal
+			n.Frac, err = p.fraction()
+		}
+	}
+	if err == nil && p.i < len(p.words) {
+		err = p.errorf("unexpected word")
+	}
+	if err != nil {
+		return Number{}, fmt.Errorf("cannot parse %q as a Sango number: %v", phrase, err)
+	}
+	return n, nil
+}
+
+// Parses the fraction prefix and digits of a decimal, after na.
+func (p *parser) fraction() (string, error) {
+	digits, ok := fractionDigits()[p.peek()]
+	if !ok {
+		return "", p.errorf("expected a fraction prefix")
+	}
+	p.i++
+	f, err := p.number()
+	if err != nil {
+		return "", err
+	}
+	frac := fmt.Sprintf("%0*d", digits, f)
+	if len(frac) > digits {
+		return "", fmt.Errorf("too many digits in %v %v", numeral(fractions[digits]), f)
+	}
+	return frac, nil
+}
+
+// Parses the day, month, and year of a date, after lâ.
+func (p *parser) date() (time.Time, error) {
+	day, err := p.number()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := p.expect(word("of-or-to")); err != nil {
+		return time.Time{}, err
+	}
+	month, ok := months()[p.peek()]
+	if !ok {
+		return time.Time{}, p.errorf("expected a month")
+	}
+	p.i++
+	if err := p.expect(word("of-or-to")); err != nil {
+		return time.Time{}, err
+	}
+	if err := p.expect(word("water-or-year")); err != nil {
+		return time.Time{}, err
+	}
+	year, err := p.number()
+	if err != nil {
+		return time.Time{}, err
+	}
+	t := time.Date(int(year), month, int(day), 0, 0, 0, 0, time.UTC)
+	if t.Day() != int(day) || int64(t.Year()) != year {
+		return time.Time{}, fmt.Errorf("no day %v in %v %v", day, month, year)
+	}
+	return t, nil
+}
+
+func (n Number) string() string {
+	switch n.Kind {
+	case KindOrdinal:
+		if n.Int == 1 {
+			return "1er"
+		}
+		return fmt.Sprintf("%ve", n.Int)
+	case KindDecimal:
+		return fmt.Sprintf("%v,%v", n.Int, n.Frac)
+	case KindDate:
+		return n.Date.Format("02/01/2006")
+	case KindCFA:
+		return fmt.Sprintf("%v F CFA", n.Int)
+	}
+	return strconv.FormatInt(n.Int, 10)
+}
+
+// Thousands may be grouped with a point or a space, e.g. 10.000 or 10 000, so
+// a number with groups of three digits after a point is not a decimal.
+var digitsRE = regexp.MustCompile(`^(?:` +
+	`(?P<date>\d{1,2}/\d{1,2}/\d{1,4})|` +
+	`(?P<cfa>\d{1,3}(?:[. ]\d{3})+|\d+) ?(?:F ?CFA|FCFA|XAF|francs? CFA|F)|` +
+	`(?P<ordinal>\d+)(?:er|re|ère|ème|e)|` +
+	`(?P<grouped>\d{1,3}(?:[. ]\d{3})+)|` +
+	`(?P<decimal>\d+[,.]\d+)|` +
+	`(?P<cardinal>\d+))`)
+
+var groupSeparators = strings.NewReplacer(".", "", " ", "")
+
+// Parses a date with a year of 4 digits, or of 2 digits in 1969 to 2068.
+func parseDate(s string) (time.Time, error) {
+	if _, year, _ := strings.Cut(s[strings.Index(s, "/")+1:], "/"); len(year) == 2 {
+		return time.Parse("2/1/06", s)
+	}
+	return time.Parse("2/1/2006", s)
+}
+
+func parseDigits(s string) (Number, error) {
+	m := digitsRE.FindStringSubmatch(s)
+	if m == nil || len(m[0]) != len(s) {
+		return Number{}, fmt.Errorf("cannot parse %q as a number in digits", s)
+	}
+	n, err := numberOf(m)
+	if err != nil {
+		return Number{}, fmt.Errorf("cannot parse %q as a number in digits: %v", s, err)
+	}
+	return n, nil
+}
+
+// Returns the number of a match of digitsRE.
+func numberOf(m []string) (Number, error) {
+	var n Number
+	var err error
+	switch {
+	case m[digitsRE.SubexpIndex("date")] != "":
+		n.Kind = KindDate
+		n.Date, err = parseDate(m[digitsRE.SubexpIndex("date")])
+	case m[digitsRE.SubexpIndex("cfa")] != "":
+		n.Kind = KindCFA
+		n.Int, err = strconv.ParseInt(groupSeparators.Replace(m[digitsRE.SubexpIndex("cfa")]), 10, 64)
+	case m[digitsRE.SubexpIndex("ordinal")] != "":
+		n.Kind = KindOrdinal
+		n.Int, err = strconv.ParseInt(m[digitsRE.SubexpIndex("ordinal")], 10, 64)
+	case m[digitsRE.SubexpIndex("grouped")] != "":
+		n.Kind = KindCardinal
+		n.Int, err = strconv.ParseInt(groupSeparators.Replace(m[digitsRE.SubexpIndex("grouped")]), 10, 64)
+	case m[digitsRE.SubexpIndex("decimal")] != "":
+		n.Kind = KindDecimal
+		whole, frac, _ := strings.Cut(strings.Replace(m[digitsRE.SubexpIndex("decimal")], ".", ",", 1), ",")
+		n.Frac = frac
+		n.Int, err = strconv.ParseInt(whole, 10, 64)
+	default:
+		n.Kind = KindCardinal
+		n.Int, err = strconv.ParseInt(m[digitsRE.SubexpIndex("cardinal")], 10, 64)
+	}
+	return n, err
+}
+
+var plainRE = regexp.MustCompile(`^(?:\d{1,3}(?:[. ]\d{3})+|\d+(?:[,.]\d+)?)`)
+
+// Returns the number in digits at the start of text, and its length in bytes.
+func numberAt(text string) (Number, int, error) {
+	m := digitsRE.FindStringSubmatch(text)
+	if m == nil {
+		return Number{}, 0, fmt.Errorf("no number in digits")
+	}
+	if continuesWord(text[len(m[0]):]) {
+		// e.g. 5 Fois is not 5 F, but 5 followed by Fois
+		end := len(plainRE.FindString(text))
+		if continuesWord(text[end:]) {
+			return Number{}, 0, fmt.Errorf("no number in digits")
+		}
+		m = digitsRE.FindStringSubmatch(text[:end])
+	}
+	n, err := numberOf(m)
+	return n, len(m[0]), err
+}
+
+func continuesWord(text string) bool {
+	for _, r := range text {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	return false
+}
+
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
+
+func normalize(text string) string {
+	var s strings.Builder
+	afterLetter := false
+	for len(text) > 0 {
+		if r, size := utf8.DecodeRuneInString(text); !isDigit(r) {
+			s.WriteString(text[:size])
+			text = text[size:]
+			afterLetter = unicode.IsLetter(r) || unicode.IsMark(r)
+			continue
+		}
+		n, size, err := numberAt(text)
+		if size == 0 || afterLetter {
+			// e.g. 2nd or A4: keep the digits as they are
+			if size = strings.IndexFunc(text, func(r rune) bool { return !isDigit(r) }); size < 0 {
+				size = len(text)
+			}
+			s.WriteString(text[:size])
+		} else if err != nil {
+			s.WriteString(text[:size]) // e.g. 31/02/2024: keep the whole number as it is
+		} else if spelled, err := spell(n); err != nil {
+			s.WriteString(text[:size]) // e.g. too large: keep the number as it is
+		} else {
+			s.WriteString(spelled)
+		}
+		text = text[size:]
+		afterLetter = true
+	}
+	return s.String()
+}
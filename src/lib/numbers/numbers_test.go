@@ -0,0 +1,161 @@
+package numbers
+
+import (
+	"testing"
+)
+
+func TestCardinal(t *testing.T) {
+	for _, test := range []struct {
+		n        int64
+		expected string
+	}{
+		{0, "pärä"},
+		{1, "ɔ̂kɔ"},
+		{7, "mbârâmbârâ"},
+		{10, "balë"},
+		{12, "balë na ûse"},
+		{20, "balë-ûse"},
+		{25, "balë-ûse na ɔkü"},
+		{100, "ngbangbo"},
+		{300, "ngbangbo otâ"},
+		{1998, "sâki na ngbangbo gümbâyä na balë-gümbâyä na meambe"},
+		{2_000_000, "kûtu ûse"},
+		{3_000_000_000, "ngbundangbu otâ"},
+	} {
+		if actual, err := Cardinal(test.n); err != nil || actual != test.expected {
+			t.Errorf("Cardinal(%v) = %q, %v; expected %q", test.n, actual, err, test.expected)
+		}
+	}
+	for _, n := range []int64{-1, MaxCardinal + 1} {
+		if _, err := Cardinal(n); err == nil {
+			t.Errorf("Cardinal(%v): expected an error", n)
+		}
+	}
+}
+
+func TestSpellAndParse(t *testing.T) {
+	for _, test := range []struct {
+		digits, spelled string
+	}{
+		{"0", "pärä"},
+		{"25", "balë-ûse na ɔkü"},
+		{"2005", "sâki ûse na ɔkü"},
+		{"2500", "sâki ûse na ngbangbo ɔkü"},
+		{"205", "ngbangbo ûse na ɔkü"},
+		{"25000", "sâki balë-ûse-na-ɔkü"},
+		{"20005", "sâki balë-ûse na ɔkü"},
+		{"305000", "sâki ngbangbo-otâ-na-ɔkü"},
+		{"300005", "sâki ngbangbo-otâ na ɔkü"},
+		{"100010", "sâki ngbangbo na balë"},
+		{"110000", "sâki ngbangbo-na-balë"},
+		{"1000000", "kûtu"},
+		{"1er", "kɔ̂zɔ"},
+		{"3,25", "otâ na zɛgbɛ balë-ûse na ɔkü"},
+		{"0,5", "pärä na sûî ɔkü"},
+		{"2,005", "ûse na yakɛ̂rɛ̂ ɔkü"},
+		{"01/01/2000", "lâ ɔ̂kɔ tî nyɛnyɛ tî ngû sâki ûse"},
+		{"18/10/2026", "lâ balë na meambe tî ngbɛrɛrɛ tî ngû sâki ûse na balë-ûse na omɛnë"},
+		{"1000 F CFA", "pâta ngbangbo ûse"},
+	} {
+		n, err := ParseDigits(test.digits)
+		if err != nil {
+			t.Errorf("ParseDigits(%q): %v", test.digits, err)
+			continue
+		}
+		if n.String() != test.digits {
+			t.Errorf("ParseDigits(%q).String() = %q", test.digits, n.String())
+		}
+		if spelled, err := Spell(n); err != nil || spelled != test.spelled {
+			t.Errorf("Spell(%v) = %q, %v; expected %q", test.digits, spelled, err, test.spelled)
+		}
+		if parsed, err := Parse(test.spelled); err != nil || parsed != n {
+			t.Errorf("Parse(%q) = %v, %v; expected %v", test.spelled, parsed, err, test.digits)
+		}
+	}
+}
+
+func TestCardinalRoundTrip(t *testing.T) {
+	var ns []int64
+	for n := int64(0); n <= 3000; n++ {
+		ns = append(ns, n, n*1000, n*1000+n%100, n*1_000_000+n, n*1_001_001_001%(MaxCardinal+1))
+	}
+	for n := int64(1); n <= MaxCardinal; n = n*7 + 3 {
+		ns = append(ns, n, n*1000%(MaxCardinal+1)+n%1000)
+	}
+	ns = append(ns, MaxCardinal)
+	for _, n := range ns {
+		spelled, err := Cardinal(n)
+		if err != nil {
+			t.Errorf("Cardinal(%v): %v", n, err)
+			continue
+		}
+		if parsed, err := Parse(spelled); err != nil || parsed != (Number{Kind: KindCardinal, Int: n}) {
+			t.Errorf("Parse(Cardinal(%v) = %q) = %v, %v", n, spelled, parsed, err)
+		}
+	}
+}
+
+func TestParseDigits(t *testing.T) {
+	for _, test := range []struct {
+		digits, expected string
+	}{
+		{"10.000", "10000"},
+		{"10 000", "10000"},
+		{"1.234.567", "1234567"},
+		{"3.25", "3,25"},
+		{"2.5", "2,5"},
+		{"10.000 F", "10000 F CFA"},
+		{"25 000 F CFA", "25000 F CFA"},
+		{"1/1/26", "01/01/2026"},
+		{"31/12/99", "31/12/1999"},
+	} {
+		if n, err := ParseDigits(test.digits); err != nil || n.String() != test.expected {
+			t.Errorf("ParseDigits(%q) = %v, %v; expected %v", test.digits, n, err, test.expected)
+		}
+	}
+	for _, digits := range []string{"31/02/2024", "1/1/5", "1/1/026"} {
+		if n, err := ParseDigits(digits); err == nil {
+			t.Errorf("ParseDigits(%q) = %v, expected an error", digits, n)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, test := range []struct {
+		phrase, expected string
+	}{
+		{"bale-use na oku", "25"},
+		{"ngbangbo oko", "100"},
+		{"Lâ ôko tî Sepe tî ngû sâki ûse", "01/01/2000"},
+		{"pata bale", "50 F CFA"},
+	} {
+		if n, err := Parse(test.phrase); err != nil || n.String() != test.expected {
+			t.Errorf("Parse(%q) = %v, %v; expected %v", test.phrase, n, err, test.expected)
+		}
+	}
+	for _, phrase := range []string{"", "na", "ûse na", "ûse ûse", "balë-balë", "lâ balë-otâ na ûse tî nyɛnyɛ tî ngû sâki ûse", "ɔ̂kɔ na zɛgbɛ ngbangbo"} {
+		if n, err := Parse(phrase); err == nil {
+			t.Errorf("Parse(%q) = %v, expected an error", phrase, n)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for _, test := range []struct {
+		text, expected string
+	}{
+		{"Mbï yeke na 25 ngû.", "Mbï yeke na balë-ûse na ɔkü ngû."},
+		{"Lo futa 500 F CFA lâ 2/3/2024.", "Lo futa pâta ngbangbo lâ lâ ûse tî mbängü tî ngû sâki ûse na balë-ûse na usïö."},
+		{"A4, 2nd, 3,1415 na 1000000000000", "A4, 2nd, 3,1415 na 1000000000000"},
+		{"1er, 2e, 1,5 F", "kɔ̂zɔ, ûse, ɔ̂kɔ na sûî ɔkü F"},
+		{"5 Fois", "ɔkü Fois"},
+		{"10.000 F", "pâta sâki ûse"},
+		{"Âla 10 000 na 3.5", "Âla sâki balë na otâ na sûî ɔkü"},
+		{"lâ 1/1/26", "lâ lâ ɔ̂kɔ tî nyɛnyɛ tî ngû sâki ûse na balë-ûse na omɛnë"},
+		{"31/02/2024 na 1/1/5", "31/02/2024 na 1/1/5"},
+	} {
+		if actual := Normalize(test.text); actual != test.expected {
+			t.Errorf("Normalize(%q)\nexpected: %q\nactual:   %q", test.text, test.expected, actual)
+		}
+	}
+}
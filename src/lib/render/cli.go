@@ -0,0 +1,50 @@
+package render
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zokwezo/sango/src/lib/corpus"
+)
+
+func Init(rootCmd *cobra.Command) {
+	renderCmd.Flags().StringVar(&formatFlagValue, "format", "html", "Output format: html, gb4e, or expex.")
+	renderCmd.Flags().StringVar(&translationsFlagValue, "translations", "en,fr", "Comma-separated languages of the text_xx free translations to include.")
+	rootCmd.AddCommand(renderCmd)
+}
+
+var (
+	formatFlagValue       string
+	translationsFlagValue string
+
+	renderCmd = &cobra.Command{
+		Use:   "render [file.conllu...]",
+		Short: "Read CoNLL-U from files (or stdin), render interlinear glossed text as HTML or LaTeX, then write to stdout",
+		Run: func(cmd *cobra.Command, args []string) {
+			sentences, err := corpus.ReadFiles(args)
+			if err != nil {
+				log.Fatal(err)
+			}
+			var langs []string
+			for _, lang := range strings.Split(translationsFlagValue, ",") {
+				if lang = strings.TrimSpace(lang); lang != "" {
+					langs = append(langs, lang)
+				}
+			}
+			out := bufio.NewWriter(os.Stdout)
+			defer out.Flush()
+			switch formatFlagValue {
+			case "html":
+				err = WriteHTML(out, sentences, langs)
+			default:
+				err = WriteLaTeX(out, sentences, langs, formatFlagValue)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+)
@@ -0,0 +1,228 @@
+// Renders CoNLL-U corpora as interlinear glossed text (IGT) for publication.
+//
+// Each sentence is rendered as its Sango words aligned with their per-token
+// MISC Gloss= values, followed by the free translations taken from the
+// text_en, text_fr, or text_de sentence comments.
+// Following the Leipzig glossing rules, a prefix (FEATS Prefix=Yes) is joined to
+// its host word with a hyphen, multiword glosses are joined with periods, and
+// punctuation is attached to the adjacent word without a gloss of its own.
+
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/zokwezo/sango/src/lib/corpus"
+)
+
+// One aligned column of interlinear glossed text.
+type Column struct {
+	Form  string
+	Gloss string
+}
+
+// A free translation of a sentence.
+type Translation struct {
+	Lang string // e.g. "en"
+	Text string
+}
+
+// Groups the words of a sentence into aligned columns of form and gloss.
+func Columns(sentence corpus.Sentence) []Column {
+	return columns(sentence)
+}
+
+// Returns the free translations of a sentence, in the order of the given languages.
+func Translations(sentence corpus.Sentence, langs []string) []Translation {
+	var translations []Translation
+	for _, lang := range langs {
+		if text := sentence.Comment("text_" + lang); text != "" {
+			translations = append(translations, Translation{Lang: lang, Text: text})
+		}
+	}
+	return translations
+}
+
+// Writes a standalone HTML document with one aligned IGT block per sentence.
+func WriteHTML(out io.Writer, sentences []corpus.Sentence, langs []string) error {
+	return writeHTML(out, sentences, langs)
+}
+
+// Valid LaTeX styles are "gb4e" and "expex".
+func WriteLaTeX(out io.Writer, sentences []corpus.Sentence, langs []string, style string) error {
+	return writeLaTeX(out, sentences, langs, style)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+func columns(sentence corpus.Sentence) []Column {
+	var cols []Column
+	pendingForm := ""  // opening punctuation and prefixes waiting for their host word
+	pendingGloss := "" // glosses of prefixes waiting for their host word
+	for _, word := range sentence.Words() {
+		if word.UPOS == "PUNCT" {
+			if isOpening(word.Form) || len(cols) == 0 || pendingForm != "" {
+				pendingForm += word.Form
+			} else {
+				cols[len(cols)-1].Form += word.Form
+			}
+			continue
+		}
+		gloss := strings.ReplaceAll(word.MiscValue("Gloss"), "-", ".")
+		if gloss == "" {
+			gloss = "?"
+		}
+		if word.FeatValue("Prefix") == "Yes" {
+			pendingForm += word.Form + "-"
+			pendingGloss += gloss + "-"
+			continue
+		}
+		cols = append(cols, Column{Form: pendingForm + word.Form, Gloss: pendingGloss + gloss})
+		pendingForm, pendingGloss = "", ""
+	}
+	if pendingForm != "" {
+		if len(cols) == 0 {
+			cols = append(cols, Column{})
+		}
+		cols[len(cols)-1].Form += strings.TrimSuffix(pendingForm, "-")
+		cols[len(cols)-1].Gloss += strings.TrimSuffix(pendingGloss, "-")
+	}
+	return cols
+}
+
+func isOpening(punctuation string) bool {
+	return strings.ContainsAny(punctuation, "«“‘([{¿¡")
+}
+
+var htmlTemplate = template.Must(template.New("igt").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>{{ .Title }}</title>
+  <style>
+    .igt { margin: 1.5em 0; }
+    .igt-id { color: #888; font-size: small; }
+    .igt-word { display: inline-block; vertical-align: top; margin-right: 0.8em; }
+    .igt-form { display: block; font-weight: bold; }
+    .igt-gloss { display: block; font-variant: small-caps; }
+    .igt-translation { margin: 0.3em 0 0 0; font-style: italic; }
+  </style>
+</head>
+<body>
+  <h1>{{ .Title }}</h1>
+{{- range .Sentences }}
+  <div class="igt"{{ if .ID }} id="{{ .ID }}"{{ end }}>
+    {{- if .ID }}
+    <div class="igt-id">{{ .ID }}</div>
+    {{- end }}
+    <div class="igt-words" lang="sg">
+      {{- range .Columns }}
+      <div class="igt-word"><span class="igt-form">{{ .Form }}</span><span class="igt-gloss" lang="en">{{ .Gloss }}</span></div>
+      {{- end }}
+    </div>
+    {{- range .Translations }}
+    <p class="igt-translation" lang="{{ .Lang }}">‘{{ .Text }}’</p>
+    {{- end }}
+  </div>
+{{- end }}
+</body>
+</html>
+`))
+
+type htmlSentence struct {
+	ID           string
+	Columns      []Column
+	Translations []Translation
+}
+
+func writeHTML(out io.Writer, sentences []corpus.Sentence, langs []string) error {
+	data := struct {
+		Title     string
+		Sentences []htmlSentence
+	}{Title: "Interlinear Glossed Text"}
+	for _, s := range sentences {
+		if id := s.Comment("newdoc id"); id != "" {
+			data.Title = id
+		}
+		data.Sentences = append(data.Sentences, htmlSentence{
+			ID:           s.Comment("sent id"),
+			Columns:      columns(s),
+			Translations: Translations(s, langs),
+		})
+	}
+	w := bufio.NewWriter(out)
+	if err := htmlTemplate.Execute(w, data); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`$`, `\$`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// Braces keep a column containing spaces together as one aligned word.
+func latexWord(s string) string {
+	s = latexEscaper.Replace(s)
+	if strings.ContainsAny(s, " \t") {
+		return "{" + s + "}"
+	}
+	return s
+}
+
+func writeLaTeX(out io.Writer, sentences []corpus.Sentence, langs []string, style string) error {
+	if style != "gb4e" && style != "expex" {
+		return fmt.Errorf("unknown LaTeX style %q (expected gb4e or expex)", style)
+	}
+	w := bufio.NewWriter(out)
+	if style == "gb4e" {
+		fmt.Fprintln(w, `\begin{exe}`)
+	}
+	for _, s := range sentences {
+		var forms, glosses []string
+		for _, c := range columns(s) {
+			forms = append(forms, latexWord(c.Form))
+			glosses = append(glosses, latexWord(c.Gloss))
+		}
+		var translations []string
+		for _, t := range Translations(s, langs) {
+			translations = append(translations, "‘"+latexEscaper.Replace(t.Text)+"’")
+		}
+		label := ""
+		if id := s.Comment("sent id"); id != "" {
+			label = `\label{` + id + `}`
+		}
+		switch style {
+		case "gb4e":
+			fmt.Fprintf(w, "\\ex%s\n\\gll %s\\\\\n%s\\\\\n", label, strings.Join(forms, " "), strings.Join(glosses, " "))
+			for _, t := range translations {
+				fmt.Fprintf(w, "\\trans %s\n", t)
+			}
+			fmt.Fprintln(w)
+		case "expex":
+			fmt.Fprintf(w, "\\ex%s\n\\begingl\n\\gla %s //\n\\glb %s //\n", label, strings.Join(forms, " "), strings.Join(glosses, " "))
+			if len(translations) > 0 {
+				fmt.Fprintf(w, "\\glft %s //\n", strings.Join(translations, " / "))
+			}
+			fmt.Fprintf(w, "\\endgl\n\\xe\n\n")
+		}
+	}
+	if style == "gb4e" {
+		fmt.Fprintln(w, `\end{exe}`)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,105 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zokwezo/sango/src/lib/corpus"
+)
+
+const conllu = `# sent id = tere-p2s1
+# text = «Tɛrɛ ahûnda kɔ̂bɛ.»
+# text_en = "Spider asks for food."
+# text_fr = Araignée demande à manger.
+1	«	_	PUNCT	_	_	_	_	_	Gloss=«
+2	Tɛrɛ	Tɛrɛ	PROPN	_	_	_	_	_	Gloss=spider
+3	a	a	PRON	_	Case=Nom|Person=3|Prefix=Yes|PronType=Art	_	_	_	Gloss=he-she-it
+4	hûnda	hûnda	VERB	_	Subcat=Tran	_	_	_	Gloss=ask
+5	kɔ̂bɛ	kɔ̂bɛ	NOUN	_	_	_	_	_	Gloss=food
+6	.	_	PUNCT	_	_	_	_	_	Gloss=.
+7	»	_	PUNCT	_	_	_	_	_	Gloss=»
+
+`
+
+func sentences(t *testing.T) []corpus.Sentence {
+	s, err := corpus.Parse(strings.NewReader(conllu))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestColumns(t *testing.T) {
+	expect := []Column{{"«Tɛrɛ", "spider"}, {"a-hûnda", "he.she.it-ask"}, {"kɔ̂bɛ.»", "food"}}
+	actual := Columns(sentences(t)[0])
+	if len(actual) != len(expect) {
+		t.Fatalf("\nexpect: %v\nactual: %v", expect, actual)
+	}
+	for k := range expect {
+		if actual[k] != expect[k] {
+			t.Errorf("\nexpect: %v\nactual: %v", expect, actual)
+		}
+	}
+}
+
+func TestWriteLaTeXGb4e(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteLaTeX(&out, sentences(t), []string{"en", "fr"}, "gb4e"); err != nil {
+		t.Fatal(err)
+	}
+	expect := `\begin{exe}
+\ex\label{tere-p2s1}
+\gll «Tɛrɛ a-hûnda kɔ̂bɛ.»\\
+spider he.she.it-ask food\\
+\trans ‘"Spider asks for food."’
+\trans ‘Araignée demande à manger.’
+
+\end{exe}
+`
+	if out.String() != expect {
+		t.Errorf("\nexpect:\n%s\nactual:\n%s", expect, out.String())
+	}
+}
+
+func TestWriteLaTeXExpex(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteLaTeX(&out, sentences(t), []string{"fr"}, "expex"); err != nil {
+		t.Fatal(err)
+	}
+	expect := `\ex\label{tere-p2s1}
+\begingl
+\gla «Tɛrɛ a-hûnda kɔ̂bɛ.» //
+\glb spider he.she.it-ask food //
+\glft ‘Araignée demande à manger.’ //
+\endgl
+\xe
+
+`
+	if out.String() != expect {
+		t.Errorf("\nexpect:\n%s\nactual:\n%s", expect, out.String())
+	}
+	if err := WriteLaTeX(&out, sentences(t), nil, "linguex"); err == nil {
+		t.Error("expected an error for an unknown style")
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteHTML(&out, sentences(t), []string{"en"}); err != nil {
+		t.Fatal(err)
+	}
+	html := out.String()
+	for _, expect := range []string{
+		`<div class="igt" id="tere-p2s1">`,
+		`<div class="igt-word"><span class="igt-form">a-hûnda</span><span class="igt-gloss" lang="en">he.she.it-ask</span></div>`,
+		`<p class="igt-translation" lang="en">‘&#34;Spider asks for food.&#34;’</p>`,
+	} {
+		if !strings.Contains(html, expect) {
+			t.Errorf("missing %s in\n%s", expect, html)
+		}
+	}
+	if strings.Contains(html, "Araignée") {
+		t.Error("unexpected French translation")
+	}
+}
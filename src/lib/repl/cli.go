@@ -0,0 +1,145 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zokwezo/sango/src/lib/ime"
+	"golang.org/x/term"
+)
+
+func Init(rootCmd *cobra.Command) {
+	replCmd.Flags().IntVar(&maxHitsFlagValue, "max_hits", DefaultSession.MaxHits, "Lexicon entries shown per word or lookup, 0 for all.")
+	replCmd.Flags().BoolVar(&sseFlagValue, "sse", false, "Show the SSEs of each line.")
+	replCmd.Flags().BoolVar(&canonFlagValue, "canon", false, "Show the Canonical encoding of each line.")
+	rootCmd.AddCommand(replCmd)
+}
+
+var (
+	maxHitsFlagValue int
+	sseFlagValue     bool
+	canonFlagValue   bool
+
+	replCmd = &cobra.Command{
+		Use:   "repl",
+		Short: "Interactive session to restore, gloss, and look up Sango (type :help)",
+		Long: "On a terminal, lines are typed with the sango ime input method (j mid, jj high, q unknown pitch;\n" +
+			"x ɛ, c ɔ; \\ for a literal key), with Up/Down for history and Ctrl-D to quit.\n" +
+			"Commands are typed as is, as are the words of :lookup and :en.\n" +
+			"Otherwise, lines are read from stdin as UTF8.\n\n" + Help,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			session := Session{ShowCanonical: canonFlagValue, ShowSSE: sseFlagValue, MaxHits: maxHitsFlagValue}
+			var err error
+			if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+				err = runTerminal(&session, fd, os.Stdin, os.Stdout)
+			} else {
+				err = runPipe(&session, os.Stdin, os.Stdout)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+)
+
+const prompt = "sango> "
+
+func runPipe(session *Session, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		quit, err := session.Eval(out, scanner.Text())
+		if err != nil || quit {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Reads each line in raw mode through the input method, then evaluates it in cooked mode.
+func runTerminal(session *Session, fd int, in io.Reader, out io.Writer) error {
+	fmt.Fprint(out, Help)
+	r := bufio.NewReader(in)
+	var history []string
+	for {
+		line, ok, err := readRawLine(fd, r, out, history)
+		if err != nil || !ok {
+			return err
+		}
+		if line != "" {
+			history = append(history, line)
+		}
+		if quit, err := session.Eval(out, line); err != nil || quit {
+			return err
+		}
+	}
+}
+
+// Reads a line with the terminal in raw mode.
+func readRawLine(fd int, r *bufio.Reader, out io.Writer, history []string) (line string, ok bool, err error) {
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", false, err
+	}
+	defer term.Restore(fd, oldState)
+	return readLine(r, out, history)
+}
+
+// Reads the keys of a line, echoing it to out. Returns ok = false at end of input.
+func readLine(r *bufio.Reader, out io.Writer, history []string) (line string, ok bool, err error) {
+	s := ime.State{}
+	k := len(history) // index into history of the line being edited
+	for {
+		fmt.Fprintf(out, "\r\x1b[K%s%s\x1b[4m%s\x1b[0m", prompt, s.Committed(), s.Composing())
+		key, _, err := r.ReadRune()
+		if err == io.EOF {
+			key = '\x04'
+		} else if err != nil {
+			return "", false, err
+		}
+		switch key {
+		case '\x03', '\x04': // Ctrl-C, Ctrl-D
+			fmt.Fprint(out, "\r\n")
+			return "", false, nil
+		case '\r', '\n':
+			fmt.Fprint(out, "\r\n")
+			return s.Flush().Committed(), true, nil
+		case '\x1b': // Up and Down arrows are ESC [ A and ESC [ B
+			if b, _ := r.ReadByte(); b != '[' {
+				continue
+			}
+			switch b, _ := r.ReadByte(); {
+			case b == 'A' && k > 0:
+				k--
+				s = ime.State{}.Insert(history[k])
+			case b == 'B' && k < len(history)-1:
+				k++
+				s = ime.State{}.Insert(history[k])
+			}
+		case ime.KeyBackspace, ime.KeyCtrlH, ime.KeyUndo:
+			s = s.Press(key)
+		default:
+			if typedAsIs(s.Committed()) {
+				s = s.Insert(string(key))
+			} else {
+				s = s.Press(key)
+			}
+		}
+	}
+}
+
+// Returns whether the next key of a line starting with committed bypasses the input method:
+// in a command word, and in the (English or toneless) word of :lookup and :en.
+func typedAsIs(committed string) bool {
+	command, _, found := strings.Cut(strings.TrimLeft(committed, " "), " ")
+	if !strings.HasPrefix(command, ":") {
+		return false
+	}
+	return !found || slices.Contains([]string{":lookup", ":l", ":en", ":e"}, command)
+}
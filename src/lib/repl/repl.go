@@ -0,0 +1,156 @@
+// Interactive Sango session for lexicographers.
+//
+// Each Sango line is restored (vowel height and pitch), glossed word by word,
+// and followed by the lexicon entries of its words, and optionally by its
+// Canonical and SSE encodings. Lines starting with ':' are commands (see help).
+
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/zokwezo/sango/src/lib/gloss"
+	"github.com/zokwezo/sango/src/lib/lexicon"
+	"github.com/zokwezo/sango/src/lib/restore"
+	"github.com/zokwezo/sango/src/lib/sse"
+)
+
+type Session struct {
+	ShowCanonical bool // after each line, show its Canonical encoding
+	ShowSSE       bool // after each line, show its SSEs
+	MaxHits       int  // lexicon entries shown per word or lookup, 0 for all
+}
+
+var DefaultSession = Session{MaxHits: 5}
+
+// Evaluates one line (a command or Sango text), writing the result to out.
+// Returns quit = true after :quit.
+func (s *Session) Eval(out io.Writer, line string) (quit bool, err error) {
+	return s.eval(out, line)
+}
+
+const Help = `Type a line of Sango to restore, gloss, and look up its words, or a command:
+  :lookup <word>   look up a Sango word, with or without tones
+  :en <word>       look up an English word
+  :canon [text]    show the Canonical encoding of text, else toggle it after each line
+  :sse [text]      show the SSEs of text, else toggle them after each line
+  :help            show this help
+  :quit            end the session
+`
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+func (s *Session) eval(out io.Writer, line string) (bool, error) {
+	w := bufio.NewWriter(out)
+	line = strings.TrimSpace(line)
+	quit := false
+	if strings.HasPrefix(line, ":") {
+		command, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+		switch command {
+		case ":lookup", ":l":
+			s.writeRows(w, lexicon.LookupSango(lexicon.LexiconRows(), arg), "")
+		case ":en", ":e":
+			s.writeRows(w, lexicon.LookupEnglish(lexicon.LexiconRows(), arg), "")
+		case ":canon", ":c":
+			if arg == "" {
+				s.ShowCanonical = !s.ShowCanonical
+				fmt.Fprintf(w, "canonical display %v\n", onOff(s.ShowCanonical))
+			} else {
+				writeCanonical(w, arg)
+			}
+		case ":sse", ":s":
+			if arg == "" {
+				s.ShowSSE = !s.ShowSSE
+				fmt.Fprintf(w, "SSE display %v\n", onOff(s.ShowSSE))
+			} else {
+				writeSSEs(w, arg)
+			}
+		case ":help", ":h", ":?":
+			w.WriteString(Help)
+		case ":quit", ":q":
+			quit = true
+		default:
+			fmt.Fprintf(w, "unknown command %v (type :help)\n", command)
+		}
+	} else if line != "" {
+		s.evalSango(w, line)
+	}
+	return quit, w.Flush()
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+func (s *Session) evalSango(w *bufio.Writer, line string) {
+	fmt.Fprintf(w, "restored: %s\n", restore.RestoreSangoVowels(line))
+	words := gloss.GlossSango(strings.NewReader(line))
+	gloss.WriteInterlinear(w, words, "")
+	var seen []string
+	for _, word := range words {
+		key := lexicon.TonelessKey(word.Form)
+		if word.UPOS == "PUNCT" || word.UPOS == "NUM" || key == "" || slices.Contains(seen, key) {
+			continue
+		}
+		seen = append(seen, key)
+		s.writeRows(w, lexicon.LookupSango(lexicon.LexiconRows(), word.Form), word.Form+": ")
+	}
+	if s.ShowCanonical {
+		writeCanonical(w, line)
+	}
+	if s.ShowSSE {
+		writeSSEs(w, line)
+	}
+}
+
+// Writes at most MaxHits rows, each prefixed by label.
+func (s *Session) writeRows(w *bufio.Writer, rows lexicon.DictRows, label string) {
+	if len(rows) == 0 {
+		fmt.Fprintf(w, "%sno lexicon entries\n", label)
+		return
+	}
+	n := len(rows)
+	if s.MaxHits > 0 && n > s.MaxHits {
+		n = s.MaxHits
+	}
+	for _, row := range rows[:n] {
+		fmt.Fprintf(w, "%s%s\t%s\t%v\t%s\n", label, row.Lemma, row.UDPos, row.Frequency, row.EnglishTranslation)
+	}
+	if n < len(rows) {
+		fmt.Fprintf(w, "%s... %v more\n", label, len(rows)-n)
+	}
+}
+
+func writeCanonical(w *bufio.Writer, text string) {
+	sses, err := sse.UTF8ToSSEs(text)
+	var c strings.Builder
+	for _, x := range sses {
+		x.WriteAsCanonicalTo(&c)
+	}
+	fmt.Fprintf(w, "canonical: %s\n", c.String())
+	if err != nil {
+		fmt.Fprintf(w, "error: %v\n", err)
+	}
+}
+
+func writeSSEs(w *bufio.Writer, text string) {
+	sses, err := sse.UTF8ToSSEs(text)
+	for _, x := range sses {
+		var c, u strings.Builder
+		x.WriteAsCanonicalTo(&c)
+		x.WriteAsUTF8To(&u)
+		fmt.Fprintf(w, "%016x\t%s\t%s\n", uint64(x), c.String(), u.String())
+	}
+	if err != nil {
+		fmt.Fprintf(w, "error: %v\n", err)
+	}
+}
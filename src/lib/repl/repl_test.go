@@ -0,0 +1,91 @@
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func eval(t *testing.T, s *Session, line string) string {
+	t.Helper()
+	var out bytes.Buffer
+	if _, err := s.Eval(&out, line); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestCommands(t *testing.T) {
+	s := DefaultSession
+	for _, test := range []struct {
+		line, expected string
+	}{
+		{":lookup kobe", "kɔ̂bɛ\tNOUN\t2\tfood\n"},
+		{":en pig", "kɔsɔ\tNOUN\t2\tpig\nngûru\tNOUN\t2\tpig\nmbɛ̈ngɛ̈\tNOUN\t6\tbush-pig\n"},
+		{":canon Kɔ̂bɛ!", "canonical: ~kc^bx_U+0021\n"},
+		{":sse kɔ̂bɛ", "936f115000000000\tkc^bx_\tkɔ̂bɛ\n"},
+		{":lookup xyzzy", "no lexicon entries\n"},
+		{":frobnicate", "unknown command :frobnicate (type :help)\n"},
+		{"  ", ""},
+	} {
+		if actual := eval(t, &s, test.line); actual != test.expected {
+			t.Errorf("%q:\nactual   = %q\nexpected = %q", test.line, actual, test.expected)
+		}
+	}
+	if quit, _ := s.Eval(&bytes.Buffer{}, ":quit"); !quit {
+		t.Errorf(":quit did not quit")
+	}
+}
+
+func TestSangoLine(t *testing.T) {
+	s := DefaultSession
+	if out := eval(t, &s, ":sse"); out != "SSE display on\n" {
+		t.Errorf(":sse = %q", out)
+	}
+	out := eval(t, &s, "te kɔ̂bɛ")
+	for _, expected := range []string{"restored: ", "eat.bite.gnaw food\n", "kɔ̂bɛ: kɔ̂bɛ\tNOUN\t2\tfood\n", " kc^bx_\t kɔ̂bɛ\n"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("output lacks %q:\n%s", expected, out)
+		}
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	history := []string{":lookup kobe", "kɔ̂bɛ"}
+	for _, test := range []struct {
+		keys, expected string
+	}{
+		// Sango text goes through the input method.
+		{"te kjjcbx\r", "te kɔ̂bɛ"},
+		{"ta\\xi\r", "taxi"},
+		{":canon kjjcbx\r", ":canon kɔ̂bɛ"},
+		{":sse jjax\r", ":sse âɛ"},
+		// Command words, and the words of :lookup and :en, do not.
+		{":canon\r", ":canon"},
+		{":c\r", ":c"},
+		{":sse\r", ":sse"},
+		{":en box\r", ":en box"},
+		{":e exit\r", ":e exit"},
+		{":lookup taxi\r", ":lookup taxi"},
+		{"  :l jaqo\r", "  :l jaqo"},
+		{":quit\r", ":quit"},
+		// Backspace and undo still edit a command.
+		{":cx\x7fanon\r", ":canon"},
+		{":en bot\x1ax\r", ":en box"},
+		// Up and Down recall the history.
+		{"\x1b[A\x1b[A\r", ":lookup kobe"},
+		{"\x1b[A\x1b[A\x1b[B x\r", "kɔ̂bɛ ɛ"},
+		{"\x1b[A\x1b[A ax\r", ":lookup kobe ax"},
+	} {
+		r := bufio.NewReader(strings.NewReader(test.keys))
+		if line, ok, err := readLine(r, &bytes.Buffer{}, history); err != nil || !ok || line != test.expected {
+			t.Errorf("%q: actual = %q, %v, %v; expected %q", test.keys, line, ok, err, test.expected)
+		}
+	}
+	for _, keys := range []string{"", "kobe", ":en\x04"} {
+		if line, ok, err := readLine(bufio.NewReader(strings.NewReader(keys)), &bytes.Buffer{}, history); err != nil || ok {
+			t.Errorf("%q: actual = %q, %v, %v; expected end of input", keys, line, ok, err)
+		}
+	}
+}
@@ -10,10 +10,13 @@ import (
 )
 
 func Init(rootCmd *cobra.Command) {
+	restoreCmd.Flags().BoolVar(&spellNumbersFlagValue, "spell_numbers", false, "Spell out numbers written in digits, e.g. 25 -> balë-ûse na ɔkü.")
 	rootCmd.AddCommand(restoreCmd)
 }
 
 var (
+	spellNumbersFlagValue bool
+
 	restoreCmd = &cobra.Command{
 		Use:   "restore",
 		Short: "A CLI to restore vowel height and pitch to Sango text",
@@ -28,7 +31,7 @@ var (
 			defer out.Flush()
 			s := string(b)
 
-			restored := RestoreSangoVowels(s)
+			restored := RestoreSango(s, Options{SpellNumbers: spellNumbersFlagValue})
 			if _, err := out.WriteString(restored); err != nil {
 				panic(err)
 			}
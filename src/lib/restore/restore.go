@@ -3,22 +3,27 @@
 package restore
 
 import (
-	"fmt"
 	"slices"
 	"strings"
 
 	"github.com/zokwezo/sango/src/lib/lexicon"
+	"github.com/zokwezo/sango/src/lib/numbers"
 	"github.com/zokwezo/sango/src/lib/tokenize"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
+// Keyed by lexicon.TonelessKey.
 type lexiconRowsFromTonelessMap = map[string][]*lexicon.DictRow
 
 var lexiconRowsFromToneless = func() lexiconRowsFromTonelessMap {
 	out := lexiconRowsFromTonelessMap{}
 	for _, row := range lexicon.LexiconRows() {
-		out[row.Toneless] = append(out[row.Toneless], &row)
+		if row.Toneless == "" {
+			continue
+		}
+		key := row.TonelessKey()
+		out[key] = append(out[key], &row)
 	}
 	return out
 }()
@@ -27,40 +32,49 @@ var titleCaseOf cases.Caser = cases.Title(language.Und)
 var upperCaseOf cases.Caser = cases.Upper(language.Und)
 var lowerCaseOf cases.Caser = cases.Lower(language.Und)
 
+type Options struct {
+	SpellNumbers bool // spell out numbers written in digits, e.g. 25 -> balë-ûse na ɔkü
+}
+
+// Normalizes the input as requested, then restores vowel height and pitch.
+func RestoreSango(s string, options Options) string {
+	if options.SpellNumbers {
+		// before tokenizing, which would split e.g. 1000 F CFA into separate words
+		s = numbers.Normalize(s)
+	}
+	return RestoreSangoVowels(s)
+}
+
 func RestoreSangoVowels(s string) string {
 	out := ""
-	lemmas := tokenize.ClassifySango(&s)
+	texts := tokenize.TokenTexts(strings.NewReader(s))
+	lemmas := tokenize.ClassifySango(strings.NewReader(s))
 	for k, lemma := range lemmas {
-		w := s[lemma.Source.Begin:lemma.Source.End]
-		var c cases.Caser
+		w := texts[k]
+		caseOf := func(s string) string { return s } // mixed case is left as is
 		if w == titleCaseOf.String(w) {
-			c = titleCaseOf
+			caseOf = titleCaseOf.String
 		} else if w == upperCaseOf.String(w) {
-			c = upperCaseOf
+			caseOf = upperCaseOf.String
 		} else if w == lowerCaseOf.String(w) {
-			c = lowerCaseOf
-		} else {
-			panic("Mixed case unsupported")
+			caseOf = lowerCaseOf.String
 		}
 		o := []string{}
-		fmt.Printf("Lemma[%v] = %v\n", k, lemma)
 		switch lemma.Lang {
 		case "sg":
-			for r, row := range lexiconRowsFromToneless[lemma.Toneless] {
-				if row.Frequency <= 6 && row.Sango == lowerCaseOf.String(w) {
-					o = append(o, c.String(row.Sango))
-					fmt.Printf("  Lex[%v] = {%q, %q, %q, %q, %v, %q}\n", r, row.Sango, row.UDPos, row.UDFeature, row.Category, row.Frequency, row.EnglishTranslation)
+			for _, row := range lexiconRowsFromToneless[lexicon.TonelessKey(w)] {
+				if row.Frequency <= 6 && row.Lemma == lowerCaseOf.String(w) {
+					o = append(o, caseOf(row.Lemma))
 				}
 			}
 		case "SG":
-			for r, row := range lexiconRowsFromToneless[lemma.Toneless] {
+			for _, row := range lexiconRowsFromToneless[lexicon.TonelessKey(w)] {
 				if row.Frequency <= 6 {
-					o = append(o, row.Sango)
-					fmt.Printf("  Lex[%v] = {%q, %q, %q, %q, %v, %q}\n", r, row.Sango, row.UDPos, row.UDFeature, row.Category, row.Frequency, row.EnglishTranslation)
+					o = append(o, row.Lemma)
 				}
 			}
 		default:
-			o = append(o, c.String(lemma.Sango))
+			o = append(o, w)
 		}
 		slices.Sort(o)
 		o = slices.Compact(o)
@@ -36,3 +36,19 @@ func TestRestoreSangoVowelHeightAndPitch(t *testing.T) {
 		t.Errorf("EXPECTED = %s\n", expected)
 	}
 }
+
+func TestRestoreSangoSpellNumbers(t *testing.T) {
+	for _, test := range []struct {
+		options            Options
+		original, expected string
+	}{
+		{Options{}, "25", "25"},
+		{Options{SpellNumbers: true}, "25", "balë-ûse na ɔkü"},
+		{Options{SpellNumbers: true}, "1000 F CFA", "pâta ngbangbo ûse"},
+		{Options{SpellNumbers: true}, "10.000 F", "pâta sâki ûse"},
+	} {
+		if actually := RestoreSango(test.original, test.options); actually != test.expected {
+			t.Errorf("RestoreSango(%q, %+v) = %q, expected %q", test.original, test.options, actually, test.expected)
+		}
+	}
+}
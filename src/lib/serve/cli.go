@@ -0,0 +1,34 @@
+package serve
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func Init(rootCmd *cobra.Command) {
+	serveCmd.Flags().IntVar(&portFlagValue, "port", 8080, "Port on which to serve the API.")
+	serveCmd.Flags().Int64Var(&maxRequestBytesFlagValue, "max_request_bytes", DefaultOptions.MaxRequestBytes, "Rejects request bodies larger than this.")
+	serveCmd.Flags().DurationVar(&timeoutFlagValue, "timeout", DefaultOptions.Timeout, "Abandons operations slower than this.")
+	rootCmd.AddCommand(serveCmd)
+}
+
+var (
+	portFlagValue            int
+	maxRequestBytesFlagValue int64
+	timeoutFlagValue         time.Duration
+
+	serveCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the Sango CLI operations as a JSON API under /v1",
+		Long:  "The API is described by GET /v1/openapi.json",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			server := newServer(fmt.Sprintf(":%v", portFlagValue), Options{MaxRequestBytes: maxRequestBytesFlagValue, Timeout: timeoutFlagValue})
+			log.Printf("Serving http://localhost:%v/v1/openapi.json", portFlagValue)
+			log.Fatal(server.ListenAndServe())
+		},
+	}
+)
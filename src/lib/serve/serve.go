@@ -0,0 +1,463 @@
+// JSON REST API for the Sango CLI operations.
+//
+// Every route is under the /v1 prefix and exchanges JSON (see openapi.json).
+// Request bodies are limited in size and reading time, and read in full before the operation runs,
+// each operation is bounded by a timeout, and every failure is reported as {"error": {"code": ..., "message": ...}}.
+
+package serve
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zokwezo/sango/src/lib/lexicon"
+	"github.com/zokwezo/sango/src/lib/restore"
+	"github.com/zokwezo/sango/src/lib/sse"
+	"github.com/zokwezo/sango/src/lib/tokenize"
+	"github.com/zokwezo/sango/src/lib/transcode"
+	"github.com/zokwezo/sango/src/lib/transliterate"
+)
+
+type Options struct {
+	MaxRequestBytes int64         // larger request bodies are rejected with 413
+	Timeout         time.Duration // slower operations are abandoned with 503
+}
+
+var DefaultOptions = Options{MaxRequestBytes: 1 << 20, Timeout: 10 * time.Second}
+
+type TextRequest struct {
+	Text string `json:"text"`
+}
+
+type TextResponse struct {
+	Text string `json:"text"`
+}
+
+type Token struct {
+	Text     string `json:"text"`
+	Type     string `json:"type"` // SPACE, NUM, PUNC, WORD, or OTHER
+	Lang     string `json:"lang"` // sg, Sg, SG, fr, en, or XX
+	Toneless string `json:"toneless,omitempty"`
+}
+
+type TokenizeResponse struct {
+	Tokens []Token `json:"tokens"`
+}
+
+type SSEEncodeResponse struct {
+	Canonical string   `json:"canonical"`
+	SSEs      []string `json:"sses"` // 16 hex digits each
+}
+
+type SSEDecodeRequest struct {
+	Canonical string `json:"canonical"`
+}
+
+type LexiconRow struct {
+	Index              int    `json:"index"` // into the lexicon
+	Toneless           string `json:"toneless"`
+	Heightless         string `json:"heightless"`
+	Lemma              string `json:"lemma"`
+	Canonical          string `json:"canonical"`
+	UDPos              string `json:"ud_pos"`
+	UDFeature          string `json:"ud_feature"`
+	Category           string `json:"category"`
+	Frequency          int    `json:"frequency"`
+	EnglishTranslation string `json:"english_translation"`
+	EnglishDefinition  string `json:"english_definition"`
+}
+
+type LexiconResponse struct {
+	Rows      []LexiconRow `json:"rows"`
+	Truncated bool         `json:"truncated"`
+}
+
+type Error struct {
+	Code    string `json:"code"` // bad_request, not_found, request_too_large, timeout, or internal
+	Message string `json:"message"`
+}
+
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+// Returns a handler serving the /v1 API.
+func NewHandler(options Options) http.Handler {
+	return newHandler(options)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+//go:embed openapi.json
+var openAPI []byte
+
+const (
+	defaultLookupLimit = 100
+	maxLookupLimit     = 1000
+)
+
+type apiError struct {
+	status int
+	Error
+}
+
+func badRequest(format string, a ...any) *apiError {
+	return &apiError{http.StatusBadRequest, Error{"bad_request", fmt.Sprintf(format, a...)}}
+}
+
+// Computes the response body of a request, or an error.
+//
+// The body of r has already been read into memory, and r.Context() is done
+// once the operation times out, after which its result is discarded.
+type operation func(r *http.Request) (any, *apiError)
+
+func newHandler(options Options) http.Handler {
+	mux := http.NewServeMux()
+	handle := func(pattern string, op operation) {
+		mux.Handle(pattern, handler{options, op})
+	}
+	handle("POST /v1/tokenize", tokenizeOp)
+	handle("POST /v1/transliterate/{op}", textOp("transliteration", transliterations))
+	handle("POST /v1/transcode/{op}", textOp("transcoding", transcodings))
+	handle("POST /v1/sse/encode", sseEncodeOp)
+	handle("POST /v1/sse/decode", sseDecodeOp)
+	handle("POST /v1/restore", restoreOp)
+	handle("GET /v1/lexicon/lookup", lexiconLookupOp)
+	mux.HandleFunc("GET /v1/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, &apiError{http.StatusNotFound, Error{"not_found", r.Method + " " + r.URL.Path + " is not part of the API"}})
+	})
+	return mux
+}
+
+// Time allowed to read a request, including its body, which is read before the
+// operation and its timeout start.
+const readTimeout = 30 * time.Second
+
+// Returns a server of the /v1 API, so slow clients cannot hold connections open:
+// a request must be read within readTimeout, and its response written within
+// the operation's timeout after that.
+func newServer(addr string, options Options) *http.Server {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           newHandler(options),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       readTimeout,
+	}
+	if options.Timeout > 0 {
+		server.WriteTimeout = readTimeout + options.Timeout + 10*time.Second
+	}
+	return server
+}
+
+type handler struct {
+	options Options
+	op      operation
+}
+
+type result struct {
+	body any
+	err  *apiError
+}
+
+func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// An operation that times out keeps running after this returns, so it is given
+	// a copy of the request whose body has been read here, never r.Body itself.
+	body, apiErr := readBody(w, r, h.options.MaxRequestBytes)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	ctx := r.Context()
+	if h.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.options.Timeout)
+		defer cancel()
+	}
+	req := r.Clone(ctx)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				log.Printf("panic serving %v %v: %v", req.Method, req.URL.Path, p)
+				done <- result{err: &apiError{http.StatusInternalServerError, Error{"internal", "internal error"}}}
+			}
+		}()
+		body, err := h.op(req)
+		done <- result{body, err}
+	}()
+	select {
+	case res := <-done:
+		if res.err != nil {
+			writeError(w, res.err)
+			return
+		}
+		writeJSON(w, http.StatusOK, res.body)
+	case <-ctx.Done():
+		writeError(w, &apiError{http.StatusServiceUnavailable, Error{"timeout", "operation exceeded " + h.options.Timeout.String()}})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Print(err)
+	}
+}
+
+func writeError(w http.ResponseWriter, err *apiError) {
+	writeJSON(w, err.status, ErrorResponse{err.Error})
+}
+
+// Reads the whole request body, of at most maxBytes if positive.
+func readBody(w http.ResponseWriter, r *http.Request, maxBytes int64) ([]byte, *apiError) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	in := r.Body
+	if maxBytes > 0 {
+		in = http.MaxBytesReader(w, r.Body, maxBytes)
+	}
+	body, err := io.ReadAll(in)
+	var maxBytesError *http.MaxBytesError
+	switch {
+	case err == nil:
+		return body, nil
+	case errors.As(err, &maxBytesError):
+		return nil, &apiError{http.StatusRequestEntityTooLarge, Error{"request_too_large",
+			fmt.Sprintf("request body exceeds %v bytes", maxBytesError.Limit)}}
+	default:
+		return nil, badRequest("cannot read request body: %v", err)
+	}
+}
+
+// Decodes the JSON request body into v, rejecting unknown fields and trailing data.
+// Fails instead if the request has already timed out, so as not to start the work.
+func decode(r *http.Request, v any) *apiError {
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	err := d.Decode(v)
+	if err == nil && d.More() {
+		err = errors.New("unexpected data after JSON value")
+	}
+	if err != nil {
+		return badRequest("invalid JSON request body: %v", err)
+	}
+	return abandoned(r)
+}
+
+// Returns an error once the request has timed out or been canceled.
+func abandoned(r *http.Request) *apiError {
+	if err := r.Context().Err(); err != nil {
+		return &apiError{http.StatusServiceUnavailable, Error{"timeout", err.Error()}}
+	}
+	return nil
+}
+
+func tokenizeOp(r *http.Request) (any, *apiError) {
+	var req TextRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	texts := tokenize.TokenTexts(strings.NewReader(req.Text))
+	resp := TokenizeResponse{Tokens: []Token{}}
+	for k, lemma := range tokenize.ClassifySango(strings.NewReader(req.Text)) {
+		resp.Tokens = append(resp.Tokens, Token{
+			Text:     texts[k],
+			Type:     lemma.Type,
+			Lang:     lemma.Lang,
+			Toneless: lemma.Toneless,
+		})
+	}
+	return resp, nil
+}
+
+// A CLI operation from stdin to stdout.
+type textFunc func(out *bufio.Writer, in *bufio.Reader) error
+
+var transliterations = map[string]textFunc{
+	"encode":      transliterate.Encode,
+	"decode":      transliterate.Decode,
+	"normalize":   transliterate.Normalize,
+	"unnormalize": transliterate.Unnormalize,
+}
+
+var transcodings = map[string]textFunc{
+	"encode": transcode.EncodePhrase,
+	"decode": transcode.DecodeSSEs,
+}
+
+// Returns the operation applying the text function named by the {op} path value.
+func textOp(kind string, funcs map[string]textFunc) operation {
+	var names []string
+	for name := range funcs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return func(r *http.Request) (any, *apiError) {
+		f, ok := funcs[r.PathValue("op")]
+		if !ok {
+			return nil, &apiError{http.StatusNotFound, Error{"not_found",
+				fmt.Sprintf("unknown %v %q: expected one of %v", kind, r.PathValue("op"), strings.Join(names, ", "))}}
+		}
+		var req TextRequest
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		if err := f(bufio.NewWriter(&b), bufio.NewReader(strings.NewReader(req.Text))); err != nil {
+			return nil, badRequest("%v", err)
+		}
+		return TextResponse{b.String()}, nil
+	}
+}
+
+func sseEncodeOp(r *http.Request) (any, *apiError) {
+	var req TextRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	sses, err := sse.UTF8ToSSEs(req.Text)
+	if err != nil {
+		return nil, badRequest("%v", err)
+	}
+	resp := SSEEncodeResponse{SSEs: []string{}}
+	var c strings.Builder
+	for _, x := range sses {
+		x.WriteAsCanonicalTo(&c)
+		resp.SSEs = append(resp.SSEs, fmt.Sprintf("%016x", uint64(x)))
+	}
+	resp.Canonical = c.String()
+	return resp, nil
+}
+
+func sseDecodeOp(r *http.Request) (any, *apiError) {
+	var req SSEDecodeRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	sses, err := sse.CanonicalToSSEs(req.Canonical)
+	if err != nil {
+		return nil, badRequest("%v", err)
+	}
+	var b strings.Builder
+	for _, x := range sses {
+		x.WriteAsUTF8To(&b)
+	}
+	return TextResponse{b.String()}, nil
+}
+
+func restoreOp(r *http.Request) (any, *apiError) {
+	var req TextRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return TextResponse{restore.RestoreSangoVowels(req.Text)}, nil
+}
+
+func lexiconLookupOp(r *http.Request) (any, *apiError) {
+	q := r.URL.Query()
+	limit := defaultLookupLimit
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxLookupLimit {
+			return nil, badRequest("limit must be an integer from 1 to %v", maxLookupLimit)
+		}
+		limit = n
+	}
+
+	var rows lexicon.DictRows
+	switch {
+	case q.Has("word"):
+		rows = lexicon.LookupSango(lexicon.LexiconRows(), q.Get("word"))
+	case q.Has("english"):
+		rows = lexicon.LookupEnglish(lexicon.LexiconRows(), q.Get("english"))
+	default:
+		f := lexicon.DictRowRegexp{}
+		for _, p := range []struct {
+			name string
+			re   **regexp.Regexp
+		}{
+			{"toneless", &f.TonelessRE},
+			{"heightless", &f.HeightlessRE},
+			{"lemma", &f.LemmaRE},
+			{"canonical", &f.CanonicalRE},
+			{"ud_pos", &f.UDPosRE},
+			{"ud_feature", &f.UDFeatureRE},
+			{"category", &f.CategoryRE},
+			{"english_translation", &f.EnglishTranslationRE},
+			{"english_definition", &f.EnglishDefinitionRE},
+		} {
+			if s := q.Get(p.name); s != "" {
+				re, err := regexp.Compile(s)
+				if err != nil {
+					return nil, badRequest("invalid %v regexp: %v", p.name, err)
+				}
+				*p.re = re
+			}
+		}
+		for _, p := range []struct {
+			name string
+			n    *int
+		}{
+			{"frequency_min", &f.FrequencyMin},
+			{"frequency_max", &f.FrequencyMax},
+		} {
+			if s := q.Get(p.name); s != "" {
+				n, err := strconv.Atoi(s)
+				if err != nil {
+					return nil, badRequest("%v must be an integer", p.name)
+				}
+				*p.n = n
+			}
+		}
+		if f.FrequencyMax > 0 {
+			f.FrequencyMax++ // inclusive, as in the lexicon lookup CLI
+		}
+		rows = lexicon.Lookup(lexicon.LexiconRows(), f)
+	}
+	if err := abandoned(r); err != nil {
+		return nil, err
+	}
+
+	resp := LexiconResponse{Rows: []LexiconRow{}}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		resp.Truncated = true
+	}
+	for _, row := range rows {
+		resp.Rows = append(resp.Rows, LexiconRow{
+			Index:              lexicon.IndexFromRow()[row],
+			Toneless:           row.Toneless,
+			Heightless:         row.Heightless,
+			Lemma:              row.Lemma,
+			Canonical:          row.Canonical,
+			UDPos:              row.UDPos,
+			UDFeature:          row.UDFeature,
+			Category:           row.Category,
+			Frequency:          row.Frequency,
+			EnglishTranslation: row.EnglishTranslation,
+			EnglishDefinition:  row.EnglishDefinition,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,211 @@
+package serve
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func do(t *testing.T, h http.Handler, method, target, body string, v any) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%v %v: Content-Type = %q", method, target, ct)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Errorf("%v %v: %v in %s", method, target, err, w.Body.String())
+	}
+	return w.Code
+}
+
+func TestTransliterate(t *testing.T) {
+	h := NewHandler(DefaultOptions)
+	var resp TextResponse
+	if code := do(t, h, "POST", "/v1/transliterate/decode", `{"text": "kcjbx"}`, &resp); code != http.StatusOK {
+		t.Fatalf("status = %v", code)
+	}
+	if resp.Text != "kɔ̂bɛ" {
+		t.Errorf("decode = %q", resp.Text)
+	}
+	if do(t, h, "POST", "/v1/transliterate/encode", `{"text": "kɔ̂bɛ"}`, &resp); resp.Text != "kcjbx" {
+		t.Errorf("encode = %q", resp.Text)
+	}
+}
+
+func TestTranscode(t *testing.T) {
+	h := NewHandler(DefaultOptions)
+	var resp TextResponse
+	if code := do(t, h, "POST", "/v1/transcode/encode", `{"text": "kɔ̂bɛ"}`, &resp); code != http.StatusOK {
+		t.Fatalf("status = %v", code)
+	}
+	if !strings.HasPrefix(resp.Text, "There are 2 tokens") {
+		t.Errorf("encode = %q", resp.Text)
+	}
+	if code := do(t, h, "POST", "/v1/transcode/decode", `{"text": ""}`, &resp); code != http.StatusOK {
+		t.Errorf("decode status = %v", code)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	h := NewHandler(DefaultOptions)
+	var resp TokenizeResponse
+	if code := do(t, h, "POST", "/v1/tokenize", `{"text": "Kɔ̂bɛ tî taxi CFA."}`, &resp); code != http.StatusOK {
+		t.Fatalf("status = %v", code)
+	}
+	var texts []string
+	for _, token := range resp.Tokens {
+		texts = append(texts, token.Text)
+	}
+	if strings.Join(texts, "|") != "Kɔ̂bɛ| |tî| |taxi| |CFA|." {
+		t.Errorf("tokens = %q", texts)
+	}
+}
+
+func TestSSE(t *testing.T) {
+	h := NewHandler(DefaultOptions)
+	var encoded SSEEncodeResponse
+	if code := do(t, h, "POST", "/v1/sse/encode", `{"text": "kɔ̂bɛ"}`, &encoded); code != http.StatusOK {
+		t.Fatalf("status = %v", code)
+	}
+	if encoded.Canonical != "kc^bx_" || len(encoded.SSEs) != 1 || len(encoded.SSEs[0]) != 16 {
+		t.Errorf("encode = %+v", encoded)
+	}
+	var decoded TextResponse
+	if do(t, h, "POST", "/v1/sse/decode", `{"canonical": "kc^bx_"}`, &decoded); decoded.Text != "kɔ̂bɛ" {
+		t.Errorf("decode = %q", decoded.Text)
+	}
+}
+
+func TestLexiconLookup(t *testing.T) {
+	h := NewHandler(DefaultOptions)
+	var resp LexiconResponse
+	if code := do(t, h, "GET", "/v1/lexicon/lookup?word=kobe", "", &resp); code != http.StatusOK {
+		t.Fatalf("status = %v", code)
+	}
+	if len(resp.Rows) == 0 || resp.Rows[0].Lemma != "kɔ̂bɛ" || resp.Rows[0].Index == 0 {
+		t.Errorf("word=kobe: %+v", resp)
+	}
+	if do(t, h, "GET", "/v1/lexicon/lookup?toneless=^ng&limit=2", "", &resp); len(resp.Rows) != 2 || !resp.Truncated {
+		t.Errorf("toneless=^ng&limit=2: %+v", resp)
+	}
+	for _, row := range resp.Rows {
+		if !strings.HasPrefix(row.Toneless, "ng") {
+			t.Errorf("toneless=^ng: %+v", row)
+		}
+	}
+	if do(t, h, "GET", "/v1/lexicon/lookup?frequency_min=1&frequency_max=1&limit=1000", "", &resp); len(resp.Rows) == 0 {
+		t.Errorf("frequency_max=1: no rows")
+	}
+	for _, row := range resp.Rows {
+		if row.Frequency != 1 {
+			t.Errorf("frequency_max=1: %+v", row)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	h := NewHandler(Options{MaxRequestBytes: 64, Timeout: time.Second})
+	for _, test := range []struct {
+		method, target, body string
+		status               int
+		code                 string
+	}{
+		{"POST", "/v1/transliterate/decode", `{"text": 1}`, http.StatusBadRequest, "bad_request"},
+		{"POST", "/v1/transliterate/decode", `{"txt": "a"}`, http.StatusBadRequest, "bad_request"},
+		{"POST", "/v1/transliterate/decode", `{"text": "a"} {}`, http.StatusBadRequest, "bad_request"},
+		{"POST", "/v1/transliterate/decode", `{"text": "` + strings.Repeat("a", 64) + `"}`, http.StatusRequestEntityTooLarge, "request_too_large"},
+		{"POST", "/v1/transliterate/reverse", `{"text": "a"}`, http.StatusNotFound, "not_found"},
+		{"POST", "/v1/transcode/normalize", `{"text": "a"}`, http.StatusNotFound, "not_found"},
+		{"POST", "/v1/sse/decode", `{"canonical": "?kc^bx_"}`, http.StatusBadRequest, "bad_request"},
+		{"GET", "/v1/lexicon/lookup?lemma=(", "", http.StatusBadRequest, "bad_request"},
+		{"GET", "/v1/lexicon/lookup?limit=0", "", http.StatusBadRequest, "bad_request"},
+		{"GET", "/v2/tokenize", "", http.StatusNotFound, "not_found"},
+	} {
+		var resp ErrorResponse
+		if status := do(t, h, test.method, test.target, test.body, &resp); status != test.status || resp.Error.Code != test.code {
+			t.Errorf("%v %v %s: %v %+v, expected %v %v", test.method, test.target, test.body, status, resp, test.status, test.code)
+		}
+	}
+}
+
+func TestTimeoutAndPanic(t *testing.T) {
+	options := Options{Timeout: 10 * time.Millisecond}
+	slow := handler{options, func(r *http.Request) (any, *apiError) {
+		<-r.Context().Done()
+		return TextResponse{}, nil
+	}}
+	var resp ErrorResponse
+	if status := do(t, slow, "POST", "/", "", &resp); status != http.StatusServiceUnavailable || resp.Error.Code != "timeout" {
+		t.Errorf("slow: %v %+v", status, resp)
+	}
+	panicky := handler{options, func(r *http.Request) (any, *apiError) { panic("oops") }}
+	if status := do(t, panicky, "POST", "/", "", &resp); status != http.StatusInternalServerError || resp.Error.Code != "internal" {
+		t.Errorf("panicky: %v %+v", status, resp)
+	}
+
+	// An operation outliving its handler still reads the whole body, but not from the request.
+	returned, proceed, read := &atomic.Bool{}, make(chan struct{}), make(chan string, 1)
+	late := handler{options, func(r *http.Request) (any, *apiError) {
+		<-proceed
+		b, _ := io.ReadAll(r.Body)
+		read <- string(b)
+		return TextResponse{}, nil
+	}}
+	w := httptest.NewRecorder()
+	late.ServeHTTP(w, httptest.NewRequest("POST", "/", lateReader{strings.NewReader(`{"text": "kɔ̂bɛ"}`), returned, t}))
+	returned.Store(true)
+	close(proceed)
+	if w.Code != http.StatusServiceUnavailable || <-read != `{"text": "kɔ̂bɛ"}` {
+		t.Errorf("late: %v %s", w.Code, w.Body)
+	}
+}
+
+// Fails the test if read once the handler has returned.
+type lateReader struct {
+	io.Reader
+	returned *atomic.Bool
+	t        *testing.T
+}
+
+func (r lateReader) Read(p []byte) (int, error) {
+	if r.returned.Load() {
+		r.t.Error("request body read after the handler returned")
+	}
+	return r.Reader.Read(p)
+}
+
+func TestOpenAPI(t *testing.T) {
+	var spec struct {
+		OpenAPI string         `json:"openapi"`
+		Paths   map[string]any `json:"paths"`
+	}
+	if code := do(t, NewHandler(DefaultOptions), "GET", "/v1/openapi.json", "", &spec); code != http.StatusOK {
+		t.Fatalf("status = %v", code)
+	}
+	for _, path := range []string{"/tokenize", "/transliterate/{op}", "/transcode/{op}", "/sse/encode", "/sse/decode", "/restore", "/lexicon/lookup"} {
+		if spec.Paths[path] == nil {
+			t.Errorf("openapi.json lacks %v", path)
+		}
+	}
+}
+
+func TestServerTimeouts(t *testing.T) {
+	server := newServer(":0", Options{Timeout: time.Second})
+	if server.ReadHeaderTimeout <= 0 || server.ReadTimeout < server.ReadHeaderTimeout {
+		t.Errorf("ReadHeaderTimeout = %v, ReadTimeout = %v", server.ReadHeaderTimeout, server.ReadTimeout)
+	}
+	// Writing the response starts once the headers are read, so it is given the
+	// time to read the body and run the operation as well.
+	if server.WriteTimeout <= server.ReadTimeout+time.Second {
+		t.Errorf("WriteTimeout = %v, ReadTimeout = %v", server.WriteTimeout, server.ReadTimeout)
+	}
+	if server := newServer(":0", Options{}); server.WriteTimeout != 0 {
+		t.Errorf("WriteTimeout = %v for unbounded operations", server.WriteTimeout)
+	}
+}
@@ -0,0 +1,89 @@
+package features
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zokwezo/sango/src/lib/corpus"
+	"github.com/zokwezo/sango/src/lib/lexicon"
+)
+
+func Init(rootCmd *cobra.Command) {
+	featuresCmd.PersistentFlags().StringVar(&encodingFlagValue, "encoding", "onehot", "Encoding of each feature: onehot (one column per value) or dense (one column holding the 1-based index of the value).")
+	featuresCmd.PersistentFlags().StringVar(&formatFlagValue, "format", "csv", "Output format: csv (one row per word) or npy (a NumPy uint8 array of shape (words, syllables, columns)).")
+	featuresCmd.PersistentFlags().IntVar(&syllablesFlagValue, "syllables", 0, "Number of syllables per word, truncated or padded with zeros (0 for the longest word).")
+	featuresCmd.PersistentFlags().StringVar(&labelsFlagValue, "labels", "", "If nonempty, also write the word of each row to this file, one per line.")
+	featuresCmd.AddCommand(lexiconCmd)
+	featuresCmd.AddCommand(corpusCmd)
+	rootCmd.AddCommand(featuresCmd)
+}
+
+var (
+	encodingFlagValue  string
+	formatFlagValue    string
+	syllablesFlagValue int
+	labelsFlagValue    string
+
+	featuresCmd = &cobra.Command{
+		Use:   "features",
+		Short: "A CLI to export per-syllable feature vectors of Sango words for machine learning",
+	}
+
+	lexiconCmd = &cobra.Command{
+		Use:     "lexicon",
+		Short:   "Write the features of each lexicon lemma to stdout",
+		Example: "  sango features lexicon --format npy --labels lemmas.txt > lemmas.npy",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			write(OfLexicon(lexicon.LexiconRows(), encodingOf(encodingFlagValue), syllablesFlagValue))
+		},
+	}
+
+	corpusCmd = &cobra.Command{
+		Use:     "corpus [file.conllu...]",
+		Short:   "Read CoNLL-U from files (or stdin), then write the features of each token to stdout",
+		Example: "  sango features corpus --encoding dense corpora/*.conllu > tokens.csv",
+		Run: func(cmd *cobra.Command, args []string) {
+			sentences, err := corpus.ReadFiles(args)
+			if err != nil {
+				log.Fatal(err)
+			}
+			write(OfCorpus(sentences, encodingOf(encodingFlagValue), syllablesFlagValue))
+		},
+	}
+)
+
+func encodingOf(name string) Encoding {
+	encoding, ok := EncodingOf(name)
+	if !ok {
+		log.Fatalf("Unknown --encoding %q: expected onehot or dense", name)
+	}
+	return encoding
+}
+
+func write(t *Tensor) {
+	out := bufio.NewWriter(os.Stdout)
+	var err error
+	switch formatFlagValue {
+	case "csv":
+		err = t.WriteCSV(out)
+	case "npy":
+		err = t.WriteNPY(out)
+	default:
+		log.Fatalf("Unknown --format %q: expected csv or npy", formatFlagValue)
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if labelsFlagValue != "" {
+		if err := os.WriteFile(labelsFlagValue, []byte(strings.Join(t.Labels, "\n")+"\n"), 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+// Feature vectors of Sango words, for machine learning.
+//
+// Each syllable of a word is described by a fixed list of categorical features
+// (consonant class, place, and voicing, vowel height and backness, nasality,
+// pitch, and a preceding hyphen), encoded either as one-hot vectors (one column
+// per value of each feature) or densely (one column per feature, holding the
+// 1-based index of its value, for use with an embedding layer). Either way, a
+// row of zeros pads a word out to a fixed number of syllables.
+//
+// A Tensor holds the features of many words, of shape (words, syllables, columns),
+// and can be written as a NumPy .npy file (of uint8) or as CSV (one row per word).
+
+package features
+
+import (
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/zokwezo/sango/src/lib/corpus"
+	"github.com/zokwezo/sango/src/lib/lexicon"
+	"github.com/zokwezo/sango/src/lib/sse"
+)
+
+type Encoding int
+
+const (
+	OneHot Encoding = iota // one column per value of each feature, 1 if it has that value
+	Dense                  // one column per feature, the 1-based index of its value
+)
+
+// Returns the encoding with this name (onehot or dense).
+func EncodingOf(name string) (Encoding, bool) {
+	for e, n := range encodingNames {
+		if n == name {
+			return e, true
+		}
+	}
+	return 0, false
+}
+
+func (e Encoding) String() string {
+	return encodingNames[e]
+}
+
+// A categorical feature of a syllable.
+type Feature struct {
+	Name   string
+	Values []string
+	Of     func(sse.Syllable) int // index into Values
+}
+
+// The features of each syllable, in column order.
+var Features = []Feature{
+	{"consonant_class", []string{"none", "stop", "prenasalized", "fricative", "nasal", "liquid", "glide"}, consonantClassOf},
+	{"consonant_place", []string{"none", "labial", "labiodental", "alveolar", "palatal", "velar", "labiovelar", "glottal"}, consonantPlaceOf},
+	{"consonant_voicing", []string{"none", "voiceless", "voiced"}, consonantVoicingOf},
+	{"vowel_height", []string{"high", "close_mid", "mid", "open_mid", "low"}, vowelHeightOf},
+	{"vowel_backness", []string{"front", "central", "back"}, vowelBacknessOf},
+	{"nasality", []string{"oral", "nasal"}, nasalityOf},
+	{"pitch", []string{"unknown", "low", "mid", "high"}, pitchOf},
+	{"hyphen", []string{"no", "yes"}, hyphenOf},
+}
+
+// Returns the name of each column of a syllable, e.g. pitch=high (OneHot) or pitch (Dense).
+func Columns(encoding Encoding) []string {
+	return columns(encoding)
+}
+
+// Returns the features of one syllable.
+func OfSyllable(s sse.Syllable, encoding Encoding) []uint8 {
+	return ofSyllable(s, encoding)
+}
+
+// Returns the features of each Sango syllable of a word (ignoring any Unicode
+// runes), truncated or padded with zeros to numSyllables.
+func OfWord(sses []sse.SSE, encoding Encoding, numSyllables int) [][]uint8 {
+	return ofWord(sses, encoding, numSyllables)
+}
+
+type Tensor struct {
+	Labels    []string // the word of each row
+	Syllables int      // the number of syllables per word
+	Columns   []string // the name of each column per syllable
+	Data      []uint8  // in row-major order
+}
+
+// The shape of the tensor: (words, syllables, columns).
+func (t *Tensor) Shape() [3]int {
+	return [3]int{len(t.Labels), t.Syllables, len(t.Columns)}
+}
+
+// Returns the features of the lemma of each lexicon row with Sango syllables.
+// If numSyllables is 0, words are padded to the longest one.
+func OfLexicon(rows lexicon.DictRows, encoding Encoding, numSyllables int) *Tensor {
+	var words []word
+	for _, r := range rows {
+		if r.Toneless != "" {
+			words = append(words, word{r.Lemma, r.SSEs()})
+		}
+	}
+	return ofWords(words, encoding, numSyllables)
+}
+
+// Returns the features of the form of each corpus token with Sango syllables.
+// If numSyllables is 0, words are padded to the longest one.
+func OfCorpus(sentences []corpus.Sentence, encoding Encoding, numSyllables int) *Tensor {
+	return ofCorpus(sentences, encoding, numSyllables)
+}
+
+// Writes the tensor in NumPy .npy format (version 1.0, uint8, C order).
+func (t *Tensor) WriteNPY(out io.Writer) error {
+	return writeNPY(out, t)
+}
+
+// Writes the tensor as CSV, with a header, then one row per word: its label,
+// then its columns for each syllable, e.g. s1.pitch=high.
+func (t *Tensor) WriteCSV(out io.Writer) error {
+	return writeCSV(out, t)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+var encodingNames = map[Encoding]string{
+	OneHot: "onehot",
+	Dense:  "dense",
+}
+
+// Index of the value of each feature.
+var consonantClasses = map[sse.ConsonantCode]int{
+	sse.ConsonantCode_h: 0,
+	sse.ConsonantCode_b: 1, sse.ConsonantCode_d: 1, sse.ConsonantCode_g: 1, sse.ConsonantCode_k: 1,
+	sse.ConsonantCode_p: 1, sse.ConsonantCode_t: 1, sse.ConsonantCode_q: 1, sse.ConsonantCode_K: 1,
+	sse.ConsonantCode_B: 2, sse.ConsonantCode_D: 2, sse.ConsonantCode_G: 2, sse.ConsonantCode_Q: 2,
+	sse.ConsonantCode_P: 2, sse.ConsonantCode_V: 2, sse.ConsonantCode_Z: 2,
+	sse.ConsonantCode_H: 3, sse.ConsonantCode_f: 3, sse.ConsonantCode_s: 3, sse.ConsonantCode_v: 3,
+	sse.ConsonantCode_z: 3,
+	sse.ConsonantCode_m: 4, sse.ConsonantCode_n: 4, sse.ConsonantCode_Y: 4,
+	sse.ConsonantCode_l: 5, sse.ConsonantCode_r: 5,
+	sse.ConsonantCode_w: 6, sse.ConsonantCode_y: 6,
+}
+
+var consonantPlaces = map[sse.ConsonantCode]int{
+	sse.ConsonantCode_h: 0,
+	sse.ConsonantCode_b: 1, sse.ConsonantCode_B: 1, sse.ConsonantCode_p: 1, sse.ConsonantCode_P: 1,
+	sse.ConsonantCode_m: 1,
+	sse.ConsonantCode_f: 2, sse.ConsonantCode_v: 2, sse.ConsonantCode_V: 2,
+	sse.ConsonantCode_d: 3, sse.ConsonantCode_D: 3, sse.ConsonantCode_t: 3, sse.ConsonantCode_s: 3,
+	sse.ConsonantCode_z: 3, sse.ConsonantCode_Z: 3, sse.ConsonantCode_n: 3, sse.ConsonantCode_l: 3,
+	sse.ConsonantCode_r: 3,
+	sse.ConsonantCode_y: 4, sse.ConsonantCode_Y: 4,
+	sse.ConsonantCode_g: 5, sse.ConsonantCode_G: 5, sse.ConsonantCode_k: 5,
+	sse.ConsonantCode_q: 6, sse.ConsonantCode_Q: 6, sse.ConsonantCode_K: 6, sse.ConsonantCode_w: 6,
+	sse.ConsonantCode_H: 7,
+}
+
+var voicelessConsonants = map[sse.ConsonantCode]bool{
+	sse.ConsonantCode_p: true, sse.ConsonantCode_t: true, sse.ConsonantCode_k: true, sse.ConsonantCode_K: true,
+	sse.ConsonantCode_f: true, sse.ConsonantCode_s: true, sse.ConsonantCode_H: true,
+}
+
+// Nasal vowels and vowels of unknown height are mid.
+var vowelHeights = map[sse.VowelCode]int{
+	sse.VowelCode_i: 0, sse.VowelCode_I: 0, sse.VowelCode_u: 0, sse.VowelCode_U: 0,
+	sse.VowelCode_e: 1, sse.VowelCode_o: 1,
+	sse.VowelCode_X: 2, sse.VowelCode_C: 2, sse.VowelCode_E: 2, sse.VowelCode_O: 2,
+	sse.VowelCode_x: 3, sse.VowelCode_c: 3,
+	sse.VowelCode_a: 4, sse.VowelCode_A: 4,
+}
+
+var vowelBacknesses = map[sse.VowelCode]int{
+	sse.VowelCode_i: 0, sse.VowelCode_I: 0, sse.VowelCode_e: 0, sse.VowelCode_E: 0,
+	sse.VowelCode_X: 0, sse.VowelCode_x: 0,
+	sse.VowelCode_a: 1, sse.VowelCode_A: 1,
+	sse.VowelCode_u: 2, sse.VowelCode_U: 2, sse.VowelCode_o: 2, sse.VowelCode_O: 2,
+	sse.VowelCode_C: 2, sse.VowelCode_c: 2,
+}
+
+var nasalVowels = map[sse.VowelCode]bool{
+	sse.VowelCode_A: true, sse.VowelCode_E: true, sse.VowelCode_I: true, sse.VowelCode_O: true, sse.VowelCode_U: true,
+}
+
+var pitches = map[sse.PitchCode]int{
+	sse.PitchCode_Unknown: 0,
+	sse.PitchCode_Low:     1,
+	sse.PitchCode_Mid:     2,
+	sse.PitchCode_High:    3,
+}
+
+func consonantClassOf(s sse.Syllable) int { return consonantClasses[s.Consonant] }
+func consonantPlaceOf(s sse.Syllable) int { return consonantPlaces[s.Consonant] }
+func vowelHeightOf(s sse.Syllable) int    { return vowelHeights[s.Vowel] }
+func vowelBacknessOf(s sse.Syllable) int  { return vowelBacknesses[s.Vowel] }
+func pitchOf(s sse.Syllable) int          { return pitches[s.Pitch] }
+
+func consonantVoicingOf(s sse.Syllable) int {
+	switch {
+	case s.Consonant == sse.ConsonantCode_h:
+		return 0
+	case voicelessConsonants[s.Consonant]:
+		return 1
+	}
+	return 2
+}
+
+func nasalityOf(s sse.Syllable) int {
+	if nasalVowels[s.Vowel] {
+		return 1
+	}
+	return 0
+}
+
+func hyphenOf(s sse.Syllable) int {
+	if s.Infix == sse.InfixCode_Hyphen {
+		return 1
+	}
+	return 0
+}
+
+func columns(encoding Encoding) []string {
+	var names []string
+	for _, f := range Features {
+		if encoding == Dense {
+			names = append(names, f.Name)
+			continue
+		}
+		for _, v := range f.Values {
+			names = append(names, f.Name+"="+v)
+		}
+	}
+	return names
+}
+
+func ofSyllable(s sse.Syllable, encoding Encoding) []uint8 {
+	var row []uint8
+	for _, f := range Features {
+		k := f.Of(s)
+		if encoding == Dense {
+			row = append(row, uint8(k+1))
+			continue
+		}
+		oneHot := make([]uint8, len(f.Values))
+		oneHot[k] = 1
+		row = append(row, oneHot...)
+	}
+	return row
+}
+
+func syllablesOf(sses []sse.SSE) []sse.Syllable {
+	var syllables []sse.Syllable
+	for _, x := range sses {
+		syllables = append(syllables, x.Syllables()...)
+	}
+	return syllables
+}
+
+func ofWord(sses []sse.SSE, encoding Encoding, numSyllables int) [][]uint8 {
+	syllables := syllablesOf(sses)
+	width := len(columns(encoding))
+	rows := make([][]uint8, numSyllables)
+	for k := range rows {
+		if k < len(syllables) {
+			rows[k] = ofSyllable(syllables[k], encoding)
+		} else {
+			rows[k] = make([]uint8, width)
+		}
+	}
+	return rows
+}
+
+type word struct {
+	label string
+	sses  []sse.SSE
+}
+
+func ofWords(words []word, encoding Encoding, numSyllables int) *Tensor {
+	t := &Tensor{Syllables: numSyllables, Columns: columns(encoding)}
+	if numSyllables == 0 {
+		for _, w := range words {
+			t.Syllables = max(t.Syllables, len(syllablesOf(w.sses)))
+		}
+	}
+	for _, w := range words {
+		if len(syllablesOf(w.sses)) == 0 {
+			continue
+		}
+		t.Labels = append(t.Labels, w.label)
+		for _, row := range ofWord(w.sses, encoding, t.Syllables) {
+			t.Data = append(t.Data, row...)
+		}
+	}
+	return t
+}
+
+func ofCorpus(sentences []corpus.Sentence, encoding Encoding, numSyllables int) *Tensor {
+	var words []word
+	for _, s := range sentences {
+		for _, token := range s.Words() {
+			sses, err := sse.UTF8ToSSEs(token.Form)
+			if err != nil {
+				continue
+			}
+			words = append(words, word{token.Form, sses})
+		}
+	}
+	return ofWords(words, encoding, numSyllables)
+}
+
+func writeNPY(out io.Writer, t *Tensor) error {
+	shape := t.Shape()
+	header := fmt.Sprintf("{'descr': '|u1', 'fortran_order': False, 'shape': (%v, %v, %v), }", shape[0], shape[1], shape[2])
+	// The magic string, version, header length, and header end in a newline on a 64-byte boundary.
+	padding := 63 - (10+len(header))%64
+	header += strings.Repeat(" ", padding) + "\n"
+	b := []byte("\x93NUMPY\x01\x00")
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(header)))
+	b = append(b, header...)
+	if _, err := out.Write(b); err != nil {
+		return err
+	}
+	_, err := out.Write(t.Data)
+	return err
+}
+
+func writeCSV(out io.Writer, t *Tensor) error {
+	w := csv.NewWriter(out)
+	header := []string{"label"}
+	for k := range t.Syllables {
+		for _, c := range t.Columns {
+			header = append(header, fmt.Sprintf("s%v.%v", k+1, c))
+		}
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	width := t.Syllables * len(t.Columns)
+	for i, label := range t.Labels {
+		record := []string{label}
+		for _, v := range t.Data[i*width : (i+1)*width] {
+			record = append(record, strconv.Itoa(int(v)))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
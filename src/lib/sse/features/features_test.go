@@ -0,0 +1,83 @@
+package features
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zokwezo/sango/src/lib/corpus"
+	"github.com/zokwezo/sango/src/lib/sse"
+)
+
+func TestOfSyllable(t *testing.T) {
+	// ngû: prenasalized velar voiced, high back oral, high pitch, no hyphen
+	s := sse.Syllable{Consonant: sse.ConsonantCode_G, Vowel: sse.VowelCode_u, Pitch: sse.PitchCode_High}
+	if actual, expect := OfSyllable(s, Dense), []uint8{3, 6, 3, 1, 3, 1, 4, 1}; !slices.Equal(actual, expect) {
+		t.Errorf("bad dense features\nexpect: %v\nactual: %v", expect, actual)
+	}
+	oneHot := OfSyllable(s, OneHot)
+	columns := Columns(OneHot)
+	if len(oneHot) != len(columns) {
+		t.Fatalf("%v one-hot features for %v columns", len(oneHot), len(columns))
+	}
+	var hot []string
+	for k, v := range oneHot {
+		if v == 1 {
+			hot = append(hot, columns[k])
+		}
+	}
+	expect := "consonant_class=prenasalized consonant_place=velar consonant_voicing=voiced vowel_height=high " +
+		"vowel_backness=back nasality=oral pitch=high hyphen=no"
+	if actual := strings.Join(hot, " "); actual != expect {
+		t.Errorf("bad one-hot features\nexpect: %v\nactual: %v", expect, actual)
+	}
+}
+
+func TestOfWord(t *testing.T) {
+	sses, _ := sse.UTF8ToSSEs("kɔ̂bɛ")
+	rows := OfWord(sses, Dense, 3)
+	if len(rows) != 3 {
+		t.Fatalf("%v rows, expected 3", len(rows))
+	}
+	if !slices.Equal(rows[1], []uint8{2, 2, 3, 4, 1, 1, 2, 1}) {
+		t.Errorf("bad features of bɛ: %v", rows[1])
+	}
+	if !slices.Equal(rows[2], make([]uint8, len(Features))) {
+		t.Errorf("bad padding: %v", rows[2])
+	}
+}
+
+func TestWriteTensor(t *testing.T) {
+	sentences, err := corpus.Parse(strings.NewReader("1\tTɛrɛ\t_\t_\t_\t_\t_\t_\t_\t_\n2\tna\t_\t_\t_\t_\t_\t_\t_\t_\n3\t.\t_\t_\t_\t_\t_\t_\t_\t_\n\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tensor := OfCorpus(sentences, Dense, 0)
+	if shape := tensor.Shape(); shape != [3]int{2, 2, len(Features)} {
+		t.Fatalf("bad shape %v", shape)
+	}
+
+	var npy bytes.Buffer
+	if err := tensor.WriteNPY(&npy); err != nil {
+		t.Fatal(err)
+	}
+	b := npy.Bytes()
+	n := int(binary.LittleEndian.Uint16(b[8:10]))
+	header := string(b[10 : 10+n])
+	if !strings.HasPrefix(string(b), "\x93NUMPY\x01\x00") || (10+n)%64 != 0 || !strings.HasSuffix(header, "\n") ||
+		!strings.Contains(header, "'shape': (2, 2, 8)") || !bytes.Equal(b[10+n:], tensor.Data) {
+		t.Errorf("bad npy header %q", header)
+	}
+
+	var csv strings.Builder
+	if err := tensor.WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "label,s1.consonant_class,") ||
+		lines[2] != "na,5,4,3,5,2,1,2,1,0,0,0,0,0,0,0,0" {
+		t.Errorf("bad csv\n%v", csv.String())
+	}
+}
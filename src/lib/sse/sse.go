@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode/utf16"
 )
 
 type SSE uint64
@@ -56,16 +57,19 @@ func CanonicalToSSEs(s string) ([]SSE, error) {
 	return canonicalToSSEs(s)
 }
 
+// Parses UTF8 text (e.g. a lexicon Lemma or a corpus sentence) into SSEs.
+func UTF8ToSSEs(s string) ([]SSE, error) {
+	return utf8ToSSEs(s)
+}
+
 func UnpadRight(word uint64) uint64 {
-	if word&0x_8000_0000_0000_0000 != 0 {
-		// Sango SSE
-		for word&0xFFF == 0 {
-			word >>= 12
+	if kindOf(word) == KindSango {
+		for word&syllableMask == 0 {
+			word >>= syllableBits
 		}
 	} else {
-		// Unicode SSE
-		for word&0xFFFF == 0 {
-			word >>= 16
+		for word&unicodeMask == 0 {
+			word >>= unicodeBits
 		}
 	}
 	return word
@@ -74,8 +78,8 @@ func UnpadRight(word uint64) uint64 {
 func PadRight(word uint64) uint64 {
 	// First try treating as a Sango SSE
 	w := word
-	for w&0x0_FFF_000_000_000_000 == 0 {
-		w <<= 12
+	for w&syllableBitsAt(syllableMask, 0) == 0 {
+		w <<= syllableBits
 		if w == 0 {
 			break
 		}
@@ -84,8 +88,8 @@ func PadRight(word uint64) uint64 {
 		return w
 	}
 	// Treat as a Unicode SSE
-	for w&0xFFFF_0000_0000_0000 == 0 {
-		w <<= 16
+	for w&(unicodeMask<<(64-unicodeBits)) == 0 {
+		w <<= unicodeBits
 		if w == 0 {
 			return w
 		}
@@ -95,138 +99,167 @@ func PadRight(word uint64) uint64 {
 
 //////////////////////////////////////////////////////////////////////////////
 // IMPLEMENTATION
-// TODO: Stop working with bits directly, use named constants instead.
 
 func writeUTF8To(s *strings.Builder, b uint64, options WriteUTF8Options) {
-	if (b >> 63) == 0 { // up to 4 unicode runes
-		rr := [4]rune{}
-		for k := range 4 {
-			rr[3-k] = rune(b & 0xFFFF)
-			b >>= 16
-		}
-		for _, r := range rr {
-			if r != 0 {
-				s.WriteRune(r)
-			}
-		}
-	} else { // up to 5 Sango syllables
-		p0 := uint16(b >> 60 << 12)
-		p := p0               // all but the first syllable
-		p &= ^PrefixCode_MASK // force no space
-		if getShiftCode(p) == ShiftCode_Title {
-			p &= ^ShiftCode_MASK         // force no shift
-			p |= uint16(ShiftCode_lower) // set lowercase
-		}
-		cc := [5]uint16{}
-		for k := range 4 {
-			cc[4-k] = uint16(b&0xFFF) | p
-			b >>= 12
-		}
-		cc[0] = uint16(b&0xFFF) | p0
-		for _, c := range cc {
-			s.WriteString(utf8FromSangoCodeValue(c, options))
-		}
+	if kindOf(b) != KindSango {
+		s.WriteString(string(utf16.Decode(utf16Units(b))))
+		return
+	}
+	for _, code := range writtenCodes(b) {
+		s.WriteString(utf8FromSangoCodeValue(code, options))
 	}
 }
 
 func writeAsCanonicalTo(s *strings.Builder, b uint64) {
-	if (b >> 63) == 0 { // up to 4 unicode runes
-		rr := [4]rune{}
-		for k := range 4 {
-			rr[3-k] = rune(b & 0xFFFF)
-			b >>= 16
-		}
-		for _, r := range rr {
-			if r != 0 {
-				s.WriteString(fmt.Sprintf("%U", r))
-			}
-		}
-	} else { // up to 5 Sango syllables
-		p0 := uint16(b >> 60)
-		p := p0             // all but the first syllable
-		if p&0b11 == 0b10 { // if Titlecase
-			p &= 0b1000 // force no space
-			p |= 0b0001 // force lowercase
-		} else { // else not Titlecase
-			p &= 0b1011 // force no space, preserve case
+	if kindOf(b) != KindSango {
+		for _, r := range utf16Units(b) {
+			s.WriteString(fmt.Sprintf("%U", r))
 		}
-		p0 <<= 12
-		p <<= 12
-		cc := [5]uint16{}
-		for k := range 4 {
-			cc[4-k] = uint16(b&0xFFF) | p
-			b >>= 12
-		}
-		cc[0] = uint16(b&0xFFF) | p0
-		for _, c := range cc {
-			s.WriteString(canonicalFromSangoCodeValue(c))
+		return
+	}
+	for _, code := range writtenCodes(b) {
+		s.WriteString(canonicalFromSangoCodeValue(code))
+	}
+}
+
+// Returns the codes of the syllables of a Sango SSE as they are written: only
+// the first may follow a space, and only the first of a Titlecase word is Titlecase.
+func writtenCodes(b uint64) []uint16 {
+	codes := syllableCodes(b)
+	for k := 1; k < len(codes); k++ {
+		if getShiftCode(codes[k]) == ShiftCode_Title {
+			codes[k] = codes[k]&^ShiftCode_MASK | uint16(ShiftCode_lower)
 		}
 	}
+	return codes
 }
 
-// We are okay with truncating a Unicode rune from 32 bits to 16 bits which
-// is enough to express the entire Basic Multilingual Plane (BMP), since
-// higher planes have only obscure glyphs. However, forgoing even one more bit
-// would rule out interesting runes such as CJK glyphs and yet the MSB is needed
-// to determine whether a code stores a Unicode rune or a Sango syllable.
-// Consequently, the uint16 value must be supplemented with a separate bool
-// to indicate the code variant type.
+// A Unicode code is a UTF-16 code unit, which is enough to express the entire
+// Basic Multilingual Plane (BMP). Runes of the higher planes (e.g. emoji) take
+// two codes, their UTF-16 surrogate pair, which are always stored in the same SSE.
+// However, forgoing even one more bit would rule out interesting runes such as
+// CJK glyphs and yet the MSB is needed to determine whether a code stores a
+// Unicode rune or a Sango syllable. Consequently, the uint16 value must be
+// supplemented with a separate bool to indicate the code variant type.
 type sseCode struct {
 	value   uint16 // if isSango is true, the MSB must be set to 1
 	isSango bool
 }
 
-// Returns the accumulated codes and the index where processing stopped.
-// The latter will equal len(s) on success, else the index of the first error.
-func canonicalToCodes(s string) ([]sseCode, int) {
+// Returns the accumulated codes and the errors where processing stopped: at the
+// first one, unless lenient, in which case unparseable text is skipped.
+func canonicalToCodes(s string, lenient bool) ([]sseCode, []*ParseError) {
 	// Initialize return values
 	var codes []sseCode
+	var errs []*ParseError
 
-	// Partition string into codes.
-	indexes := canonicalRE.FindAllStringSubmatchIndex(s, -1)
-
-	// Bail out if the start of string doesn't match.
-	n := len(indexes)
-	if n == 0 || indexes[0][0] != 0 {
-		return codes, 0
+	// Records an error, and returns whether to stop.
+	fail := func(err *ParseError) bool {
+		errs = append(errs, err)
+		return !lenient
 	}
 
-	for k := range n {
-		ii := indexes[k]
-		if len(ii) != 14 {
-			panic("Bad index length")
+	// A high surrogate is dropped unless followed by a low surrogate.
+	highSurrogateAt := -1 // index of a high surrogate code awaiting its low surrogate
+	dropHighSurrogate := func() bool {
+		if highSurrogateAt == -1 {
+			return false
 		}
+		codes = codes[:len(codes)-1]
+		err := newParseError(s, highSurrogateAt, len(codes), ComponentUnicode,
+			s[highSurrogateAt:highSurrogateAt+6], "U+DC00-U+DFFF (low surrogate) after it")
+		highSurrogateAt = -1
+		return fail(err)
+	}
 
-		// Bail out if there is a gap between codes.
-		if k > 0 && indexes[k][0] != indexes[k-1][1] {
-			return codes, indexes[k-1][1]
+	// Partition string into codes, each a match of canonicalRE (with 6 groups).
+	at := 0             // end of the previous match
+	afterVowel := false // whether the previous match is a syllable without pitch
+	for _, ii := range canonicalRE.FindAllStringSubmatchIndex(s, -1) {
+		// Report any gap before the code.
+		if ii[0] != at {
+			if dropHighSurrogate() {
+				return codes, errs
+			}
+			if fail(diagnose(s, at, len(codes), afterVowel)) {
+				return codes, errs
+			}
 		}
+		at, afterVowel = ii[1], false
 
 		if ii[2] != -1 { // Unicode code
 			value, err := strconv.ParseUint(s[ii[2]:ii[3]], 16, 16)
 			if err != nil {
-				return codes, ii[0]
+				if fail(newParseError(s, ii[0], len(codes), ComponentUnicode, s[ii[0]:ii[1]], "U+XXXX (4 uppercase hex digits)")) {
+					return codes, errs
+				}
+				continue
+			}
+			switch {
+			case isHighSurrogate(uint16(value)) && highSurrogateAt == -1:
+				highSurrogateAt = ii[0]
+			case isLowSurrogate(uint16(value)) && highSurrogateAt != -1:
+				highSurrogateAt = -1
+			case highSurrogateAt != -1:
+				if dropHighSurrogate() {
+					return codes, errs
+				}
+				if isHighSurrogate(uint16(value)) {
+					highSurrogateAt = ii[0]
+				}
+			case isLowSurrogate(uint16(value)):
+				if fail(newParseError(s, ii[0], len(codes), ComponentUnicode, s[ii[0]:ii[1]],
+					"U+D800-U+DBFF (high surrogate) before it")) {
+					return codes, errs
+				}
+				continue
 			}
 			if value != 0 {
 				codes = append(codes, sseCode{value: uint16(value), isSango: false})
 			}
 		} else { // Sango syllable
+			if dropHighSurrogate() {
+				return codes, errs
+			}
 			affix := s[ii[4]:ii[5]]
 			shift := s[ii[6]:ii[7]]
 			consonant := s[ii[8]:ii[9]]
 			vowel := s[ii[10]:ii[11]]
 			pitch := s[ii[12]:ii[13]]
 			value, err := canonicalToSangoCodeValue(affix, shift, consonant, vowel, pitch)
-			if err != nil {
-				return codes, ii[0]
-			}
-			if !IsValid(value) {
-				panic("bad value returned from canonicalToSangoCodeValue")
+			if err != nil || !IsValid(value) {
+				// Unreachable, since canonicalRE only matches valid components.
+				if fail(diagnose(s, ii[0], len(codes), false)) {
+					return codes, errs
+				}
+				continue
 			}
 			codes = append(codes, sseCode{value: value, isSango: true})
+			afterVowel = pitch == ""
 		}
 	}
-	return codes, len(s)
+	if dropHighSurrogate() {
+		return codes, errs
+	}
+	// Report any trailing text that is not a code.
+	if at != len(s) {
+		fail(diagnose(s, at, len(codes), afterVowel))
+	}
+	return codes, errs
+}
+
+func isHighSurrogate(value uint16) bool { return 0xD800 <= value && value < 0xDC00 }
+func isLowSurrogate(value uint16) bool  { return 0xDC00 <= value && value < 0xE000 }
+
+// Returns the nonzero UTF-16 code units of a Unicode SSE.
+func utf16Units(b uint64) []uint16 {
+	var units []uint16
+	for k := range unicodeCodes {
+		if unit := unicodeCodeAt(b, k); unit != 0 {
+			units = append(units, unit)
+		}
+	}
+	return units
 }
 
 func codesToSSEs(codes []sseCode) []SSE {
@@ -237,9 +270,9 @@ func codesToSSEs(codes []sseCode) []SSE {
 	var msb4 uint64
 	flush := func() {
 		if prevIsSango {
-			sse <<= (60 - 12*numCodesSaved)
+			sse <<= 64 - globalBits - syllableBits*numCodesSaved
 		} else {
-			sse <<= (64 - 16*numCodesSaved)
+			sse <<= 64 - unicodeBits*numCodesSaved
 		}
 		sse |= msb4
 		sses = append(sses, SSE(sse))
@@ -252,19 +285,16 @@ func codesToSSEs(codes []sseCode) []SSE {
 				continue
 			}
 		}
-		for count := range 10 {
-			if count > 5 {
-				panic("infinite loop")
-			}
+		for range 5 { // a code restarts the loop at most 3 times before it is saved
 			if numCodesSaved == 0 {
 				prevIsSango = code.isSango
 				msb4 = 0
 				if code.isSango {
-					msb4 = uint64(code.value) >> 12 << 60
+					msb4 = globalBitsOf(code.value)
 				}
 			}
-			if code.isSango && numCodesSaved == 5 ||
-				!code.isSango && numCodesSaved == 4 {
+			if prevIsSango && numCodesSaved == MaxSyllables ||
+				!prevIsSango && numCodesSaved == unicodeCodes {
 				// current SSE is full, flush buffer and restart
 				sse |= msb4
 				sses = append(sses, SSE(sse))
@@ -277,6 +307,11 @@ func codesToSSEs(codes []sseCode) []SSE {
 				flush()
 				continue // restart loop
 			}
+			if !code.isSango && numCodesSaved == 3 && isHighSurrogate(code.value) {
+				// a surrogate pair must not be split between two SSEs
+				flush()
+				continue // restart loop
+			}
 			switch code.isSango {
 			case false:
 				if code.value > 0x7FFF && numCodesSaved == 0 {
@@ -285,11 +320,11 @@ func codesToSSEs(codes []sseCode) []SSE {
 					numCodesSaved = 1
 					continue // restart loop
 				}
-				sse <<= 16
-				sse |= uint64(code.value & 0xFFFF)
+				sse <<= unicodeBits
+				sse |= uint64(code.value)
 			case true:
-				sse <<= 12
-				sse |= uint64(code.value & 0xFFF)
+				sse <<= syllableBits
+				sse |= uint64(code.value & syllableMask)
 			}
 			numCodesSaved += 1
 			break // move on to the next code
@@ -303,17 +338,5 @@ func codesToSSEs(codes []sseCode) []SSE {
 }
 
 func canonicalToSSEs(s string) ([]SSE, error) {
-	var err error
-	codes, b := canonicalToCodes(s)
-	n := len(s)
-	if b != n {
-		e := b + 10
-		etc := "..."
-		if e >= n {
-			e = n
-			etc = ""
-		}
-		err = fmt.Errorf("cannot parse Canonical string starting at s[%v:] = %q", b, s[b:e]+etc)
-	}
-	return codesToSSEs(codes), err
+	return canonicalToSSEsWithOptions(s, ParseOptions{})
 }
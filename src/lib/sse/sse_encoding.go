@@ -0,0 +1,145 @@
+// SSE Encodings
+//
+// An SSE implements the standard encoding interfaces, so that it can be used
+// directly in JSON payloads, logs, and database columns:
+//
+//	encoding.TextMarshaler,   encoding.TextUnmarshaler    Canonical format, e.g. " kc^bx_"
+//	json.Marshaler,           json.Unmarshaler            Canonical format as a JSON string
+//	encoding.BinaryMarshaler, encoding.BinaryUnmarshaler  8 bytes, big-endian
+//	fmt.Formatter                                         %s %v UTF-8, %q Canonical, %x %X %d raw
+//	sql.Scanner,              driver.Valuer               int64 (same bits), or Canonical text
+//
+// A UTF8 (an SSE by another name) marshals to JSON as UTF-8 text instead, for
+// payloads read by people rather than programs, e.g. []sse.UTF8{...}.
+//
+// Text must parse into exactly one SSE, and the empty string is the zero SSE.
+
+package sse
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// An SSE that marshals to JSON as UTF-8 text (as written by WriteAsUTF8To).
+type UTF8 SSE
+
+func (sse SSE) MarshalText() ([]byte, error) {
+	var s strings.Builder
+	sse.WriteAsCanonicalTo(&s)
+	return []byte(s.String()), nil
+}
+
+func (sse *SSE) UnmarshalText(text []byte) error {
+	return oneSSE(sse, string(text), canonicalToSSEs)
+}
+
+func (sse SSE) MarshalJSON() ([]byte, error) {
+	text, _ := sse.MarshalText()
+	return json.Marshal(string(text))
+}
+
+func (sse *SSE) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("cannot unmarshal SSE from JSON %s: expected a string", data)
+	}
+	return sse.UnmarshalText([]byte(s))
+}
+
+func (u UTF8) MarshalJSON() ([]byte, error) {
+	var s strings.Builder
+	SSE(u).WriteAsUTF8To(&s)
+	return json.Marshal(s.String())
+}
+
+func (u *UTF8) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("cannot unmarshal SSE from JSON %s: expected a string", data)
+	}
+	return oneSSE((*SSE)(u), s, utf8ToSSEs)
+}
+
+func (sse SSE) MarshalBinary() ([]byte, error) {
+	return binary.BigEndian.AppendUint64(nil, uint64(sse)), nil
+}
+
+func (sse *SSE) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return fmt.Errorf("cannot unmarshal SSE from %v bytes: expected 8", len(data))
+	}
+	*sse = SSE(binary.BigEndian.Uint64(data))
+	return nil
+}
+
+// Formats an SSE as UTF-8 (%s, %v), quoted Canonical format (%q), or its raw
+// bits (%x, %X, %d, %b, %o), with any flags, width, and precision of the verb.
+func (sse SSE) Format(f fmt.State, verb rune) {
+	format(f, verb, sse)
+}
+
+// Returns the bits of the SSE as an int64, e.g. for a bigint column.
+func (sse SSE) Value() (driver.Value, error) {
+	return int64(sse), nil
+}
+
+// Scans an integer (the bits of the SSE) or text in Canonical format; NULL is the zero SSE.
+func (sse *SSE) Scan(src any) error {
+	return scan(sse, src)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+// Parses s into exactly one SSE, or the zero SSE if s is empty.
+func oneSSE(sse *SSE, s string, parse func(string) ([]SSE, error)) error {
+	sses, err := parse(s)
+	if err != nil {
+		return err
+	}
+	switch len(sses) {
+	case 0:
+		*sse = 0
+	case 1:
+		*sse = sses[0]
+	default:
+		return fmt.Errorf("cannot unmarshal %q into one SSE: it has %v", s, len(sses))
+	}
+	return nil
+}
+
+func format(f fmt.State, verb rune, sse SSE) {
+	var s strings.Builder
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		fmt.Fprintf(f, "sse.SSE(0x%016X)", uint64(sse))
+	case verb == 's' || verb == 'v':
+		sse.WriteAsUTF8To(&s)
+		fmt.Fprintf(f, fmt.FormatString(f, 's'), s.String())
+	case verb == 'q':
+		sse.WriteAsCanonicalTo(&s)
+		fmt.Fprintf(f, fmt.FormatString(f, 'q'), s.String())
+	default:
+		fmt.Fprintf(f, fmt.FormatString(f, verb), uint64(sse))
+	}
+}
+
+func scan(sse *SSE, src any) error {
+	switch src := src.(type) {
+	case nil:
+		*sse = 0
+	case int64:
+		*sse = SSE(src)
+	case string:
+		return sse.UnmarshalText([]byte(src))
+	case []byte:
+		return sse.UnmarshalText(src)
+	default:
+		return fmt.Errorf("cannot scan %T into an SSE: expected an integer or Canonical text", src)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// SSE Phonetic Writers
+//
+// Writes Sango syllables in the International Phonetic Alphabet (IPA) or in its
+// ASCII transcription X-SAMPA, e.g. ngbâ -> IPA ᵑɡ͡bá (or ᵑɡ͡ba˥), X-SAMPA Ng_ba_H.
+//
+// Prenasalized stops are written with a superscript nasal in IPA (ᵐb, ⁿd, ᵑɡ)
+// and as a nasal-stop sequence in X-SAMPA (mb, nd, Ng). A vowel of unknown height
+// is written as a true-mid vowel (e̞, o̞ in IPA; e_o, o_o in X-SAMPA), and a vowel
+// of unknown pitch has no tone mark. X-SAMPA has no Chao tone letters, so it
+// always writes tone as the diacritics _H, _M, and _L.
+// Unicode runes (punctuation, non-Sango words) are written as is.
+
+package sse
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type ToneStyle int
+
+const (
+	ToneNone       ToneStyle = iota // no tone marks
+	ToneDiacritics                  // á ā à (IPA), a_H a_M a_L (X-SAMPA)
+	ToneChao                        // a˥ a˧ a˩ (IPA), after each syllable
+)
+
+type WriteIPAOptions struct {
+	XSAMPA                    bool // else IPA
+	Tone                      ToneStyle
+	ForSpaceUse, ForHyphenUse string // between words, and within hyphenated words
+	ForSyllableBreakUse       string // between the other syllables of a word, e.g. "."
+}
+
+var (
+	AsIPA     = WriteIPAOptions{XSAMPA: false, Tone: ToneDiacritics, ForSpaceUse: " ", ForHyphenUse: ".", ForSyllableBreakUse: ""}
+	AsIPAChao = WriteIPAOptions{XSAMPA: false, Tone: ToneChao, ForSpaceUse: " ", ForHyphenUse: ".", ForSyllableBreakUse: ""}
+	AsXSAMPA  = WriteIPAOptions{XSAMPA: true, Tone: ToneDiacritics, ForSpaceUse: " ", ForHyphenUse: ".", ForSyllableBreakUse: ""}
+)
+
+func (sse SSE) WriteIPATo(s *strings.Builder, options WriteIPAOptions) {
+	writeIPATo(s, uint64(sse), options)
+}
+
+func (sse SSE) WriteAsIPATo(s *strings.Builder) {
+	writeIPATo(s, uint64(sse), AsIPA)
+}
+
+func (sse SSE) WriteAsXSAMPATo(s *strings.Builder) {
+	writeIPATo(s, uint64(sse), AsXSAMPA)
+}
+
+// The phones of one Sango syllable, e.g. ngbâ -> {"ᵑɡ͡b", "a", "H", false}.
+type PhoneticSyllable struct {
+	Consonant string // empty for a bare vowel
+	Vowel     string // without tone
+	Tone      string // H, M, L, or empty if unknown
+	Hyphen    bool   // preceded by a hyphen within the word
+}
+
+// Returns the IPA (or X-SAMPA) phones of each syllable of a Sango SSE, or nil for Unicode runes.
+func (sse SSE) PhoneticSyllables(xsampa bool) []PhoneticSyllable {
+	return phoneticSyllables(uint64(sse), xsampa)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+type phone struct {
+	ipa, xsampa string
+}
+
+func (p phone) in(options WriteIPAOptions) string {
+	if options.XSAMPA {
+		return p.xsampa
+	}
+	return p.ipa
+}
+
+var consonantPhones = map[ConsonantCode]phone{
+	ConsonantCode_h: {"", ""},
+	ConsonantCode_H: {"h", "h"},
+	ConsonantCode_b: {"b", "b"},
+	ConsonantCode_B: {"ᵐb", "mb"},
+	ConsonantCode_q: {"ɡ͡b", "g_b"},
+	ConsonantCode_Q: {"ᵑɡ͡b", "Ng_b"},
+	ConsonantCode_d: {"d", "d"},
+	ConsonantCode_D: {"ⁿd", "nd"},
+	ConsonantCode_f: {"f", "f"},
+	ConsonantCode_g: {"ɡ", "g"},
+	ConsonantCode_G: {"ᵑɡ", "Ng"},
+	ConsonantCode_k: {"k", "k"},
+	ConsonantCode_l: {"l", "l"},
+	ConsonantCode_r: {"r", "r"},
+	ConsonantCode_m: {"m", "m"},
+	ConsonantCode_n: {"n", "n"},
+	ConsonantCode_p: {"p", "p"},
+	ConsonantCode_K: {"k͡p", "k_p"},
+	ConsonantCode_P: {"ᵐp", "mp"},
+	ConsonantCode_s: {"s", "s"},
+	ConsonantCode_t: {"t", "t"},
+	ConsonantCode_v: {"v", "v"},
+	ConsonantCode_V: {"ᶬv", "Fv"},
+	ConsonantCode_w: {"w", "w"},
+	ConsonantCode_y: {"j", "j"},
+	ConsonantCode_Y: {"ɲ", "J"},
+	ConsonantCode_z: {"z", "z"},
+	ConsonantCode_Z: {"ⁿz", "nz"},
+}
+
+var vowelPhones = map[VowelCode]phone{
+	VowelCode_a: {"a", "a"},
+	VowelCode_A: {"ã", "a~"},
+	VowelCode_X: {"e̞", "e_o"},
+	VowelCode_x: {"ɛ", "E"},
+	VowelCode_e: {"e", "e"},
+	VowelCode_E: {"ẽ", "e~"},
+	VowelCode_i: {"i", "i"},
+	VowelCode_I: {"ĩ", "i~"},
+	VowelCode_C: {"o̞", "o_o"},
+	VowelCode_c: {"ɔ", "O"},
+	VowelCode_o: {"o", "o"},
+	VowelCode_O: {"õ", "o~"},
+	VowelCode_u: {"u", "u"},
+	VowelCode_U: {"ũ", "u~"},
+}
+
+var toneDiacritics = map[PitchCode]phone{
+	PitchCode_Low:  {"\u0300", "_L"},
+	PitchCode_Mid:  {"\u0304", "_M"},
+	PitchCode_High: {"\u0301", "_H"},
+}
+
+var toneLetters = map[PitchCode]phone{
+	PitchCode_Low:  {"˩", "_L"},
+	PitchCode_Mid:  {"˧", "_M"},
+	PitchCode_High: {"˥", "_H"},
+}
+
+func writeIPATo(s *strings.Builder, b uint64, options WriteIPAOptions) {
+	if kindOf(b) != KindSango {
+		writeUTF8To(s, b, AsUTF8)
+		return
+	}
+	first := true
+	for _, code := range syllableCodes(b) {
+		if getShiftCode(code) == ShiftCode_Invisible {
+			continue
+		}
+		switch {
+		case first && getPrefixCode(code) == PrefixCode_Space:
+			s.WriteString(options.ForSpaceUse)
+		case !first && getInfixCode(code) == InfixCode_Hyphen:
+			s.WriteString(options.ForHyphenUse)
+		case !first:
+			s.WriteString(options.ForSyllableBreakUse)
+		}
+		first = false
+		s.WriteString(consonantPhones[getConsonantCode(code)].in(options))
+		vowel := vowelPhones[getVowelCode(code)].in(options)
+		switch options.Tone {
+		case ToneDiacritics:
+			vowel += toneDiacritics[getPitchCode(code)].in(options)
+		case ToneChao:
+			vowel += toneLetters[getPitchCode(code)].in(options)
+		}
+		s.WriteString(norm.NFC.String(vowel))
+	}
+}
+
+func phoneticSyllables(b uint64, xsampa bool) []PhoneticSyllable {
+	if kindOf(b) != KindSango || SSE(b).Shift() == ShiftCode_Invisible {
+		return nil
+	}
+	options := WriteIPAOptions{XSAMPA: xsampa}
+	melody := melodyOf(b)
+	var syllables []PhoneticSyllable
+	for k, s := range syllablesOf(b) {
+		syllables = append(syllables, PhoneticSyllable{
+			Consonant: consonantPhones[s.Consonant].in(options),
+			Vowel:     vowelPhones[s.Vowel].in(options),
+			Tone:      strings.TrimSuffix(melody[k:k+1], "U"),
+			Hyphen:    k > 0 && s.Infix == InfixCode_Hyphen,
+		})
+	}
+	return syllables
+}
+
+// Returns the nonempty syllables of a Sango SSE, each with the global prefix and shift bits.
+func syllableCodes(b uint64) []uint16 {
+	global := globalCodeOf(b)
+	var codes []uint16
+	for k := range MaxSyllables {
+		syllable := syllableCodeAt(b, k)
+		if getConsonantCode(syllable) == ConsonantCode_None {
+			continue // padding
+		}
+		code := syllable | global
+		if k > 0 {
+			code &= ^PrefixCode_MASK
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
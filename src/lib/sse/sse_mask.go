@@ -0,0 +1,209 @@
+// SSE Masks
+//
+// A Mask names the components of Sango words to ignore when comparing them,
+// e.g. MaskToneless equates kɔ̂bɛ, kobe, and KÔBE. Under a mask, words can be
+// tested for equality, sorted by Sango collation, and bucketed by key.
+//
+// Sango collation compares words syllable by syllable at three levels: first by
+// consonant and vowel (in the order of their codes, so that allophones are
+// adjacent), then by pitch, and last by infix, shift, and prefix.
+// Unicode runes are compared by code point and sort before Sango syllables.
+// A mask applies only to Sango words: Unicode runes are never changed.
+
+package sse
+
+import (
+	"cmp"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+type Mask uint16
+
+const (
+	IgnorePitch    Mask = 1 << iota // every pitch is PitchCode_Unknown
+	IgnoreHeight                    // ɛ and e are X, ɔ and o are C (of unknown height)
+	IgnoreNasality                  // añ is a, eñ is e, etc.
+	IgnoreShift                     // Title and UPPER are lower (Invisible is kept)
+	IgnorePrefix                    // no space before a word
+	IgnoreInfix                     // no hyphen within a word
+	MergeLR                         // r is l
+	MergeH                          // aspirated H is unaspirated h
+
+	MaskNone       Mask = 0
+	MaskHeightless      = IgnoreHeight | IgnoreShift | IgnorePrefix
+	MaskToneless        = IgnorePitch | IgnoreHeight | IgnoreShift | IgnorePrefix | IgnoreInfix
+)
+
+// Returns the SSE with the components ignored by mask set to a common value, for use as a map key.
+func (sse SSE) KeyUnder(mask Mask) SSE {
+	return SSE(keyUnder(uint64(sse), mask))
+}
+
+func (sse SSE) EqualUnder(other SSE, mask Mask) bool {
+	return keyUnder(uint64(sse), mask) == keyUnder(uint64(other), mask)
+}
+
+// Returns -1, 0, or +1 as sse sorts before, with, or after other in Sango collation under mask.
+func (sse SSE) CompareUnder(other SSE, mask Mask) int {
+	return compareCodes(maskedCodes([]SSE{sse}, mask), maskedCodes([]SSE{other}, mask))
+}
+
+// Returns a key for a word of one or more SSEs, which is equal for words equal
+// under mask even if they are split differently into SSEs, e.g. lâ-kûî and lâ kûî
+// under IgnorePrefix|IgnoreInfix. The key is UTF8 text of one rune per Unicode rune
+// or Sango syllable, so that keys can also be compared by prefix or edit distance,
+// or stored as text.
+func WordKeyUnder(sses []SSE, mask Mask) string {
+	return wordKeyUnder(sses, mask)
+}
+
+// Returns -1, 0, or +1 as word a sorts before, with, or after word b in Sango collation under mask.
+func CompareWordsUnder(a, b []SSE, mask Mask) int {
+	return compareCodes(maskedCodes(a, mask), maskedCodes(b, mask))
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+var oralFromNasal = map[VowelCode]VowelCode{
+	VowelCode_A: VowelCode_a,
+	VowelCode_E: VowelCode_e,
+	VowelCode_I: VowelCode_i,
+	VowelCode_O: VowelCode_o,
+	VowelCode_U: VowelCode_u,
+}
+
+var heightlessFromVowel = map[VowelCode]VowelCode{
+	VowelCode_x: VowelCode_X,
+	VowelCode_e: VowelCode_X,
+	VowelCode_c: VowelCode_C,
+	VowelCode_o: VowelCode_C,
+}
+
+// Masks a 16-bit Sango code.
+func maskCode(code uint16, mask Mask) uint16 {
+	consonant, vowel, pitch := getConsonantCode(code), getVowelCode(code), getPitchCode(code)
+	prefix, shift, infix := getPrefixCode(code), getShiftCode(code), getInfixCode(code)
+	if mask&IgnorePitch != 0 {
+		pitch = PitchCode_Unknown
+	}
+	if v, ok := oralFromNasal[vowel]; ok && mask&IgnoreNasality != 0 {
+		vowel = v
+	}
+	if v, ok := heightlessFromVowel[vowel]; ok && mask&IgnoreHeight != 0 {
+		vowel = v
+	}
+	if shift != ShiftCode_Invisible && mask&IgnoreShift != 0 {
+		shift = ShiftCode_lower
+	}
+	if mask&IgnorePrefix != 0 {
+		prefix = PrefixCode_None
+	}
+	if mask&IgnoreInfix != 0 {
+		infix = InfixCode_None
+	}
+	if consonant == ConsonantCode_r && mask&MergeLR != 0 {
+		consonant = ConsonantCode_l
+	}
+	if consonant == ConsonantCode_H && mask&MergeH != 0 {
+		consonant = ConsonantCode_h
+	}
+	return IsSango_MASK | uint16(prefix) | uint16(shift) | uint16(infix) |
+		uint16(consonant) | uint16(vowel) | uint16(pitch)
+}
+
+func keyUnder(b uint64, mask Mask) uint64 {
+	codes := syllableCodes(b)
+	if kindOf(b) != KindSango || len(codes) == 0 {
+		return b
+	}
+	var key uint64
+	for k, code := range codes {
+		code = maskCode(code, mask)
+		if k == 0 {
+			key = globalBitsOf(code)
+		}
+		key |= syllableBitsAt(code, k)
+	}
+	return key
+}
+
+// Returns the codes of each nonzero Unicode rune and each masked Sango syllable
+// (with its prefix and shift) of the SSEs. Sango codes are offset by sangoOffset,
+// since a rune may have the IsSango_MASK bit set.
+func maskedCodes(sses []SSE, mask Mask) []uint32 {
+	var codes []uint32
+	for _, x := range sses {
+		b := uint64(x)
+		if kindOf(b) != KindSango {
+			for _, r := range utf16Units(b) {
+				codes = append(codes, uint32(r))
+			}
+			continue
+		}
+		for _, code := range syllableCodes(b) {
+			codes = append(codes, sangoOffset+uint32(maskCode(code, mask)))
+		}
+	}
+	return codes
+}
+
+// In a word key, Sango codes are written as runes of the private use plane 15.
+const sangoKeyPlane = 0xF0000
+
+func wordKeyUnder(sses []SSE, mask Mask) string {
+	var s strings.Builder
+	codes := maskedCodes(sses, mask)
+	for k := 0; k < len(codes); k++ {
+		r := rune(codes[k])
+		switch {
+		case codes[k] >= sangoOffset:
+			r = sangoKeyPlane + rune(codes[k]-sangoOffset)
+		case utf16.IsSurrogate(r) && k+1 < len(codes):
+			if pair := utf16.DecodeRune(r, rune(codes[k+1])); pair != unicode.ReplacementChar {
+				r = pair
+				k++
+			}
+		}
+		s.WriteRune(r)
+	}
+	return s.String()
+}
+
+const sangoOffset = 0x10000
+
+// The collation weight of a code at each level. At the first level, Unicode runes
+// sort before Sango syllables.
+func primaryWeight(code uint32) int {
+	if code < sangoOffset {
+		return int(code)
+	}
+	return sangoOffset + int(uint16(getConsonantCode(uint16(code)))|uint16(getVowelCode(uint16(code))))
+}
+
+func secondaryWeight(code uint32) int {
+	if code < sangoOffset {
+		return 0
+	}
+	return int(getPitchCode(uint16(code)))
+}
+
+func tertiaryWeight(code uint32) int {
+	return int(code)
+}
+
+func compareCodes(a, b []uint32) int {
+	for _, weight := range []func(uint32) int{primaryWeight, secondaryWeight, tertiaryWeight} {
+		for k := range min(len(a), len(b)) {
+			if c := cmp.Compare(weight(a[k]), weight(b[k])); c != 0 {
+				return c
+			}
+		}
+		if c := cmp.Compare(len(a), len(b)); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
@@ -0,0 +1,169 @@
+// SSE Canonical Parse Errors
+//
+// A *ParseError locates the first text of a Canonical string that cannot be
+// parsed: its byte offset, the index of the code (Sango syllable or Unicode code
+// unit) it would have been, the component that is wrong, and what was expected
+// there instead. In lenient mode, unparseable text is skipped and every error is
+// returned (as ParseErrors), e.g.
+//
+//	sses, err := sse.CanonicalToSSEsWithOptions(c, sse.ParseOptions{Lenient: true})
+//	var pe *sse.ParseError
+//	if errors.As(err, &pe) {
+//		... // the first error
+//	}
+
+package sse
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+type Component int
+
+const (
+	ComponentUnicode   Component = iota // U+XXXX code
+	ComponentAffix                      // space or hyphen before a syllable
+	ComponentShift                      // ~ = # after the affix
+	ComponentConsonant                  // consonant letter
+	ComponentVowel                      // vowel letter
+	ComponentPitch                      // _ : ^ after the vowel
+)
+
+func (c Component) String() string {
+	return componentNames[c]
+}
+
+type ParseError struct {
+	Offset    int       // byte offset of the unparseable text in the input
+	Syllable  int       // number of codes (Sango syllables and Unicode code units) parsed before it
+	Component Component // the component that cannot be parsed
+	Found     string    // the unparseable text, or "" at the end of the input
+	Expected  []string  // the alternatives that would have been valid
+	context   string    // the input starting at Offset, abbreviated
+}
+
+func (e *ParseError) Error() string {
+	found := fmt.Sprintf("%q", e.Found)
+	if e.Found == "" {
+		found = "end of input"
+	}
+	return fmt.Sprintf("cannot parse Canonical string starting at s[%v:] = %q: code %v has bad %v %v, expected one of %v",
+		e.Offset, e.context, e.Syllable, e.Component, found, strings.Join(e.Expected, " "))
+}
+
+// Every error found by a lenient parse, in order of offset.
+type ParseErrors []*ParseError
+
+func (ee ParseErrors) Error() string {
+	var s []string
+	for _, e := range ee {
+		s = append(s, e.Error())
+	}
+	return strings.Join(s, "\n")
+}
+
+func (ee ParseErrors) Unwrap() []error {
+	var errs []error
+	for _, e := range ee {
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+type ParseOptions struct {
+	Lenient bool // skip unparseable text and return ParseErrors, else stop at the first *ParseError
+}
+
+// Parses a Canonical string into SSEs. On error, returns the SSEs parsed up to the
+// first error (or, if lenient, all but the unparseable text) and a *ParseError
+// (or, if lenient, ParseErrors).
+func CanonicalToSSEsWithOptions(s string, options ParseOptions) ([]SSE, error) {
+	return canonicalToSSEsWithOptions(s, options)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+var componentNames = map[Component]string{
+	ComponentUnicode:   "unicode",
+	ComponentAffix:     "affix",
+	ComponentShift:     "shift",
+	ComponentConsonant: "consonant",
+	ComponentVowel:     "vowel",
+	ComponentPitch:     "pitch",
+}
+
+const (
+	canonicalAffixes    = " -"
+	canonicalShifts     = "~=#"
+	canonicalConsonants = "hHbBqQdDfgGklrmnpKPstvVwyYzZ"
+	canonicalVowels     = "aAeEiIoOxcuUXC"
+	canonicalPitches    = "_:^"
+)
+
+func lettersOf(s string) []string {
+	return strings.Split(s, "")
+}
+
+func newParseError(s string, offset, syllable int, component Component, found string, expected ...string) *ParseError {
+	context := s[offset:]
+	if len(context) > 10 {
+		context = context[:10] + "..."
+	}
+	return &ParseError{Offset: offset, Syllable: syllable, Component: component, Found: found, Expected: expected, context: context}
+}
+
+// Returns the error at s[offset:], where canonicalRE does not match. If afterVowel,
+// the preceding syllable has no pitch, which may be what is missing.
+func diagnose(s string, offset, syllable int, afterVowel bool) *ParseError {
+	next := func(i int) string {
+		if i >= len(s) {
+			return ""
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		return s[i : i+size]
+	}
+	in := func(i int, letters string) bool {
+		return i < len(s) && strings.IndexByte(letters, s[i]) >= 0
+	}
+	if strings.HasPrefix(s[offset:], "U+") {
+		found := s[offset:min(offset+6, len(s))]
+		return newParseError(s, offset, syllable, ComponentUnicode, found, "U+XXXX (4 uppercase hex digits)")
+	}
+	i := offset
+	if afterVowel && !in(i, canonicalAffixes+canonicalShifts+canonicalConsonants) {
+		return newParseError(s, offset, syllable, ComponentPitch, next(i), lettersOf(canonicalPitches)...)
+	}
+	if in(i, canonicalAffixes) {
+		i++
+		if in(i, canonicalAffixes) {
+			return newParseError(s, i, syllable, ComponentAffix, next(i), lettersOf(canonicalShifts+canonicalConsonants)...)
+		}
+	}
+	if in(i, canonicalShifts) {
+		i++
+		if in(i, canonicalAffixes+canonicalShifts) {
+			return newParseError(s, i, syllable, ComponentShift, next(i), lettersOf(canonicalConsonants)...)
+		}
+	}
+	if !in(i, canonicalConsonants) {
+		return newParseError(s, i, syllable, ComponentConsonant, next(i), lettersOf(canonicalConsonants)...)
+	}
+	i++
+	return newParseError(s, i, syllable, ComponentVowel, next(i), lettersOf(canonicalVowels)...)
+}
+
+func canonicalToSSEsWithOptions(s string, options ParseOptions) ([]SSE, error) {
+	codes, errs := canonicalToCodes(s, options.Lenient)
+	sses := codesToSSEs(codes)
+	switch {
+	case len(errs) == 0:
+		return sses, nil
+	case options.Lenient:
+		return sses, ParseErrors(errs)
+	default:
+		return sses, errs[0]
+	}
+}
@@ -0,0 +1,256 @@
+// SSE Syllable Patterns
+//
+// A small pattern language over the syllables of a Sango word, e.g.
+//
+//	H L         two syllables, high then low pitch
+//	* N         ends in a nasal vowel
+//	* P *       has a prenasalized consonant
+//	ba . *      starts with ba (of any pitch), then at least one more syllable
+//	kɔ̂ *        starts with kɔ̂
+//
+// A pattern is a sequence of terms separated by spaces, which must match all the
+// syllables of a word (ignoring hyphens and spaces within it). The term . matches
+// any one syllable and * matches any number of syllables. Any other term matches
+// one syllable, and describes its consonant, vowel, and pitch, in that order,
+// each of which may be omitted to match anything:
+//
+//	consonant: a letter (b mb gb ngb d nd f g ng h k kp l m mp mv n ny p r s t v w y z nz),
+//	           or a class: C (any), P (prenasalized: mb mp mv nd ng ngb nz), 0 (none)
+//	vowel:     a letter (a e ɛ i o ɔ u), followed by ñ or n if nasal,
+//	           or a class: V (any), N (nasal), O (oral)
+//	pitch:     a diacritic on the vowel letter (â high, ä mid),
+//	           or a tone letter: H (high), M (mid), L (low), U (unknown)
+//
+// A vowel letter without a diacritic or tone letter matches any pitch, and e or ɛ
+// (o or ɔ) also matches the e (o) of unknown height.
+
+package sse
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type Pattern struct {
+	source string
+	terms  []patternTerm
+}
+
+// Compiles a pattern, or returns an error naming the term that cannot be parsed.
+func CompilePattern(s string) (*Pattern, error) {
+	return compilePattern(s)
+}
+
+// Compiles a pattern, and panics if it cannot be parsed.
+func MustCompilePattern(s string) *Pattern {
+	p, err := compilePattern(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *Pattern) String() string {
+	return p.source
+}
+
+// Reports whether the syllables of a Sango word (of one or more SSEs) match the
+// pattern. A word with Unicode runes never matches.
+func (p *Pattern) Match(sses []SSE) bool {
+	return p.match(sses)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+type patternTermKind int
+
+const (
+	termSyllable patternTermKind = iota
+	termAny                      // .
+	termRun                      // *
+)
+
+// A syllable matches a term if each of its codes is in the corresponding set,
+// where bit k of a set stands for code k (shifted to the low bits), and an empty
+// set matches any code.
+type patternTerm struct {
+	kind                        patternTermKind
+	consonants, vowels, pitches uint32
+}
+
+func consonantBit(c ConsonantCode) uint32 { return 1 << (uint16(c) >> 6) }
+func vowelBit(v VowelCode) uint32         { return 1 << (uint16(v) >> 2) }
+func pitchBit(p PitchCode) uint32         { return 1 << uint16(p) }
+
+// Longer letters come first, so that e.g. ngb is not read as n.
+var patternConsonants = []struct {
+	letter string
+	code   ConsonantCode
+}{
+	{"ngb", ConsonantCode_Q}, {"mb", ConsonantCode_B}, {"gb", ConsonantCode_q}, {"nd", ConsonantCode_D},
+	{"ng", ConsonantCode_G}, {"kp", ConsonantCode_K}, {"mp", ConsonantCode_P}, {"mv", ConsonantCode_V},
+	{"ny", ConsonantCode_Y}, {"nz", ConsonantCode_Z}, {"b", ConsonantCode_b}, {"d", ConsonantCode_d},
+	{"f", ConsonantCode_f}, {"g", ConsonantCode_g}, {"h", ConsonantCode_H}, {"k", ConsonantCode_k},
+	{"l", ConsonantCode_l}, {"m", ConsonantCode_m}, {"n", ConsonantCode_n}, {"p", ConsonantCode_p},
+	{"r", ConsonantCode_r}, {"s", ConsonantCode_s}, {"t", ConsonantCode_t}, {"v", ConsonantCode_v},
+	{"w", ConsonantCode_w}, {"y", ConsonantCode_y}, {"z", ConsonantCode_z},
+}
+
+var prenasalizedConsonants = consonantBit(ConsonantCode_B) | consonantBit(ConsonantCode_P) |
+	consonantBit(ConsonantCode_V) | consonantBit(ConsonantCode_D) | consonantBit(ConsonantCode_G) |
+	consonantBit(ConsonantCode_Q) | consonantBit(ConsonantCode_Z)
+
+// The oral and nasal vowels of each vowel letter.
+var patternVowels = map[rune]struct{ oral, nasal uint32 }{
+	'a': {vowelBit(VowelCode_a), vowelBit(VowelCode_A)},
+	'e': {vowelBit(VowelCode_e) | vowelBit(VowelCode_X), vowelBit(VowelCode_E)},
+	'ɛ': {vowelBit(VowelCode_x) | vowelBit(VowelCode_X), vowelBit(VowelCode_E)},
+	'i': {vowelBit(VowelCode_i), vowelBit(VowelCode_I)},
+	'o': {vowelBit(VowelCode_o) | vowelBit(VowelCode_C), vowelBit(VowelCode_O)},
+	'ɔ': {vowelBit(VowelCode_c) | vowelBit(VowelCode_C), vowelBit(VowelCode_O)},
+	'u': {vowelBit(VowelCode_u), vowelBit(VowelCode_U)},
+}
+
+var patternNasalVowels = vowelBit(VowelCode_A) | vowelBit(VowelCode_E) | vowelBit(VowelCode_I) |
+	vowelBit(VowelCode_O) | vowelBit(VowelCode_U)
+
+var patternOralVowels = vowelBit(VowelCode_a) | vowelBit(VowelCode_X) | vowelBit(VowelCode_x) |
+	vowelBit(VowelCode_e) | vowelBit(VowelCode_i) | vowelBit(VowelCode_C) | vowelBit(VowelCode_c) |
+	vowelBit(VowelCode_o) | vowelBit(VowelCode_u)
+
+var patternPitches = map[rune]uint32{
+	'\u0302': pitchBit(PitchCode_High),
+	'\u0308': pitchBit(PitchCode_Mid),
+	'H':      pitchBit(PitchCode_High),
+	'M':      pitchBit(PitchCode_Mid),
+	'L':      pitchBit(PitchCode_Low),
+	'U':      pitchBit(PitchCode_Unknown),
+}
+
+func compilePattern(s string) (*Pattern, error) {
+	p := &Pattern{source: s}
+	for _, text := range strings.Fields(s) {
+		term, err := compileTerm(text)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse pattern %q: %v", s, err)
+		}
+		p.terms = append(p.terms, term)
+	}
+	return p, nil
+}
+
+func compileTerm(text string) (patternTerm, error) {
+	switch text {
+	case ".":
+		return patternTerm{kind: termAny}, nil
+	case "*":
+		return patternTerm{kind: termRun}, nil
+	}
+	var term patternTerm
+	rest := norm.NFD.String(text)
+
+	// Consonant
+	switch {
+	case strings.HasPrefix(rest, "C"):
+		rest = rest[1:]
+	case strings.HasPrefix(rest, "P"):
+		term.consonants, rest = prenasalizedConsonants, rest[1:]
+	case strings.HasPrefix(rest, "0"):
+		term.consonants, rest = consonantBit(ConsonantCode_h), rest[1:]
+	default:
+		for _, c := range patternConsonants {
+			if after, ok := strings.CutPrefix(rest, c.letter); ok {
+				term.consonants, rest = consonantBit(c.code), after
+				break
+			}
+		}
+	}
+
+	// Vowel, with its nasality and pitch diacritic
+	switch {
+	case strings.HasPrefix(rest, "V"):
+		rest = rest[1:]
+	case strings.HasPrefix(rest, "N"):
+		term.vowels, rest = patternNasalVowels, rest[1:]
+	case strings.HasPrefix(rest, "O"):
+		term.vowels, rest = patternOralVowels, rest[1:]
+	default:
+		for letter, vowels := range patternVowels {
+			after, ok := strings.CutPrefix(rest, string(letter))
+			if !ok {
+				continue
+			}
+			term.vowels, rest = vowels.oral, after
+			for _, diacritic := range []rune{'\u0302', '\u0308'} {
+				if after, ok := strings.CutPrefix(rest, string(diacritic)); ok {
+					term.pitches, rest = patternPitches[diacritic], after
+				}
+			}
+			for _, nasal := range []string{"n\u0303", "n"} { // ñ in NFD
+				if after, ok := strings.CutPrefix(rest, nasal); ok {
+					term.vowels, rest = vowels.nasal, after
+					break
+				}
+			}
+			break
+		}
+	}
+
+	// Tone letter
+	for _, letter := range "HMLU" {
+		if after, ok := strings.CutPrefix(rest, string(letter)); ok {
+			if term.pitches != 0 {
+				return term, fmt.Errorf("term %q has two pitches", text)
+			}
+			term.pitches, rest = patternPitches[letter], after
+			break
+		}
+	}
+
+	if rest != "" {
+		return term, fmt.Errorf("term %q has unexpected %q", text, norm.NFC.String(rest))
+	}
+	return term, nil
+}
+
+func (t patternTerm) matches(s Syllable) bool {
+	return (t.consonants == 0 || t.consonants&consonantBit(s.Consonant) != 0) &&
+		(t.vowels == 0 || t.vowels&vowelBit(s.Vowel) != 0) &&
+		(t.pitches == 0 || t.pitches&pitchBit(s.Pitch) != 0)
+}
+
+func (p *Pattern) match(sses []SSE) bool {
+	var syllables []Syllable
+	for _, x := range sses {
+		if x.Kind() != KindSango {
+			return false
+		}
+		syllables = append(syllables, x.Syllables()...)
+	}
+	if len(syllables) == 0 {
+		return false
+	}
+	return matchTerms(p.terms, syllables)
+}
+
+func matchTerms(terms []patternTerm, syllables []Syllable) bool {
+	if len(terms) == 0 {
+		return len(syllables) == 0
+	}
+	switch terms[0].kind {
+	case termRun:
+		for k := range len(syllables) + 1 {
+			if matchTerms(terms[1:], syllables[k:]) {
+				return true
+			}
+		}
+		return false
+	case termAny:
+		return len(syllables) > 0 && matchTerms(terms[1:], syllables[1:])
+	default:
+		return len(syllables) > 0 && terms[0].matches(syllables[0]) && matchTerms(terms[1:], syllables[1:])
+	}
+}
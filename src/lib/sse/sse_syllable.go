@@ -297,7 +297,7 @@ func utf8FromSangoCodeValue(code uint16, options WriteUTF8Options) string {
 		case VowelCode_A:
 			s += "añ"
 		case VowelCode_X:
-			s += "x"
+			s += "ə"
 		case VowelCode_x:
 			s += "ɛ"
 		case VowelCode_e:
@@ -309,7 +309,7 @@ func utf8FromSangoCodeValue(code uint16, options WriteUTF8Options) string {
 		case VowelCode_I:
 			s += "iñ"
 		case VowelCode_C:
-			s += "c"
+			s += "ø"
 		case VowelCode_c:
 			s += "ɔ"
 		case VowelCode_o:
@@ -330,7 +330,7 @@ func utf8FromSangoCodeValue(code uint16, options WriteUTF8Options) string {
 		case VowelCode_A:
 			s += "äñ"
 		case VowelCode_X:
-			s += "ẍ"
+			s += "ə̈"
 		case VowelCode_x:
 			s += "ɛ̈"
 		case VowelCode_e:
@@ -342,7 +342,7 @@ func utf8FromSangoCodeValue(code uint16, options WriteUTF8Options) string {
 		case VowelCode_I:
 			s += "ïñ"
 		case VowelCode_C:
-			s += "c̈"
+			s += "ø̈"
 		case VowelCode_c:
 			s += "ɔ̈"
 		case VowelCode_o:
@@ -363,7 +363,7 @@ func utf8FromSangoCodeValue(code uint16, options WriteUTF8Options) string {
 		case VowelCode_A:
 			s += "âñ"
 		case VowelCode_X:
-			s += "x̂"
+			s += "ə̂"
 		case VowelCode_x:
 			s += "ɛ̂"
 		case VowelCode_e:
@@ -375,7 +375,7 @@ func utf8FromSangoCodeValue(code uint16, options WriteUTF8Options) string {
 		case VowelCode_I:
 			s += "îñ"
 		case VowelCode_C:
-			s += "ĉ"
+			s += "ø̂"
 		case VowelCode_c:
 			s += "ɔ̂"
 		case VowelCode_o:
@@ -396,7 +396,7 @@ func utf8FromSangoCodeValue(code uint16, options WriteUTF8Options) string {
 		case VowelCode_A:
 			s += "ạñ"
 		case VowelCode_X:
-			s += "x̣"
+			s += "ə̣"
 		case VowelCode_x:
 			s += "ɛ̣"
 		case VowelCode_e:
@@ -408,7 +408,7 @@ func utf8FromSangoCodeValue(code uint16, options WriteUTF8Options) string {
 		case VowelCode_I:
 			s += "ịñ"
 		case VowelCode_C:
-			s += "c̣"
+			s += "ø̣"
 		case VowelCode_c:
 			s += "ɔ̣"
 		case VowelCode_o:
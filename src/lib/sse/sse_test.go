@@ -1,9 +1,18 @@
 package sse
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand/v2"
+	"slices"
 	"strings"
 	"testing"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 func TestCanonicalToCodes(t *testing.T) {
@@ -26,10 +35,9 @@ func TestCanonicalToCodes(t *testing.T) {
 		s(0x9162), s(0x9595), s(0xF117), s(0xBB6E), s(0xB162),
 		s(0xB595), s(0xD089), s(0x90F6), s(0x9272), s(0x9463),
 	}
-	expectLen := len(c)
-	actual, actualLen := canonicalToCodes(c)
-	if actualLen != expectLen {
-		t.Errorf("in TestCanonicalToCodes: error found at c[%v](good: %q bad: %q\n", actualLen, c[0:actualLen], c[actualLen:])
+	actual, errs := canonicalToCodes(c, false)
+	if len(errs) != 0 {
+		t.Errorf("in TestCanonicalToCodes: %v", errs[0])
 	}
 	var actualHex string
 	for _, x := range actual {
@@ -284,9 +292,9 @@ func TestWriteEmptyAsUTF8(t *testing.T) {
 }
 
 func TestEmptyCanonicalToCodes(t *testing.T) {
-	actual, leftOffAt := canonicalToCodes("")
-	if leftOffAt != 0 {
-		t.Errorf("bad leftOffAt")
+	actual, errs := canonicalToCodes("", false)
+	if len(errs) != 0 {
+		t.Errorf("bad errs: %v", errs)
 	}
 	if len(actual) != 0 {
 		t.Errorf("found nonempty codes")
@@ -368,9 +376,17 @@ func TestBadCanonicalToSSEs(t *testing.T) {
 		s += " }"
 		return s
 	}
-	expectErr := fmt.Errorf("cannot parse Canonical string starting at s[83:] = %q", "-jo:ni^ ha...")
 	actual, actualErr := CanonicalToSSEs(c)
-	if actualErr.Error() != expectErr.Error() {
+	var pe *ParseError
+	if !errors.As(actualErr, &pe) {
+		t.Fatalf("expected *ParseError not returned from CanonicalToSSEs: %v", actualErr)
+	}
+	if pe.Offset != 84 || pe.Syllable != 16 || pe.Component != ComponentConsonant || pe.Found != "j" {
+		t.Errorf("bad ParseError %+v", *pe)
+	}
+	expectErr := `cannot parse Canonical string starting at s[84:] = "jo:ni^ ha^...": code 16 has bad consonant "j", ` +
+		`expected one of h H b B q Q d D f g G k l r m n p K P s t v V w y Y z Z`
+	if actualErr.Error() != expectErr {
 		t.Errorf("expected error not returned from CanonicalToSSEs\nactualErr = %v\nexpectErr = %v", actualErr, expectErr)
 	}
 	actualHex := dumpSSEs(actual)
@@ -380,6 +396,93 @@ func TestBadCanonicalToSSEs(t *testing.T) {
 	}
 }
 
+func TestParseErrors(t *testing.T) {
+	for _, test := range []struct {
+		c         string
+		offset    int
+		syllable  int
+		component Component
+		found     string
+	}{
+		{"kc^bx_?", 6, 2, ComponentConsonant, "?"},
+		{"kc^bx?", 5, 2, ComponentPitch, "?"},
+		{"kc^bj_", 4, 1, ComponentVowel, "j"},
+		{"kc^b", 4, 1, ComponentVowel, ""},
+		{"kc^~=bx_", 4, 1, ComponentShift, "="},
+		{"kc^ -bx_", 4, 1, ComponentAffix, "-"},
+		{"kc^U+12G4", 3, 1, ComponentUnicode, "U+12G4"},
+		{"U+0041U+DC00", 6, 1, ComponentUnicode, "U+DC00"},
+		{"U+0041U+D834kc^", 6, 1, ComponentUnicode, "U+D834"},
+		{"U+0041U+D834", 6, 1, ComponentUnicode, "U+D834"},
+	} {
+		_, err := CanonicalToSSEs(test.c)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("CanonicalToSSEs(%q) returned %v, not a *ParseError", test.c, err)
+			continue
+		}
+		if pe.Offset != test.offset || pe.Syllable != test.syllable || pe.Component != test.component || pe.Found != test.found {
+			t.Errorf("CanonicalToSSEs(%q) returned %+v", test.c, *pe)
+		}
+		if len(pe.Expected) == 0 {
+			t.Errorf("CanonicalToSSEs(%q) returned no expected alternatives", test.c)
+		}
+	}
+}
+
+func TestLenientCanonicalToSSEs(t *testing.T) {
+	c := "kc^bx_? =ha_HO:-jo:ni^U+0020U+DC00U+0041 ba^"
+	sses, err := CanonicalToSSEsWithOptions(c, ParseOptions{Lenient: true})
+	var errs ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ParseErrors, got %v", err)
+	}
+	var offsets []int
+	for _, e := range errs {
+		offsets = append(offsets, e.Offset)
+	}
+	if expect := []int{6, 16, 28}; !slices.Equal(offsets, expect) {
+		t.Errorf("bad offsets of errors\nexpect: %v\nactual: %v\n%v", expect, offsets, err)
+	}
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe != errs[0] {
+		t.Errorf("errors.As did not find the first *ParseError")
+	}
+	var s strings.Builder
+	for _, x := range sses {
+		x.WriteAsCanonicalTo(&s)
+	}
+	if expect := "kc^bx_ =ha_=HO:=ni^U+0020U+0041 ba^"; s.String() != expect {
+		t.Errorf("bad lenient parse\nexpect: %q\nactual: %q", expect, s.String())
+	}
+}
+
+func TestCanonicalToSSEsNeverPanics(t *testing.T) {
+	letters := []string{"U+", "D8", "DC", "00", "4", "G", " ", "-", "~", "=", "#", "k", "B", "a", "x", "_", "^", "?", "ɛ"}
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 10000 {
+		var c strings.Builder
+		for range rng.IntN(16) {
+			c.WriteString(letters[rng.IntN(len(letters))])
+		}
+		for _, lenient := range []bool{false, true} {
+			sses, err := CanonicalToSSEsWithOptions(c.String(), ParseOptions{Lenient: lenient})
+			for _, x := range sses {
+				if x.Kind() == KindSango && !IsValid(uint16(x>>48)|IsSango_MASK) {
+					t.Errorf("invalid SSE %016X from %q", uint64(x), c.String())
+				}
+			}
+			if err == nil {
+				continue
+			}
+			var pe *ParseError
+			if !errors.As(err, &pe) || pe.Offset < 0 || pe.Offset > c.Len() {
+				t.Errorf("bad error from %q: %v", c.String(), err)
+			}
+		}
+	}
+}
+
 func TestCanonicalToSSEsForUnknownPitch(t *testing.T) {
 	c := `~haHO-Doni =haHO-Doni haDx baha-mo-txnx ~bx-kcBitxbx-kcBitxU+96E3` +
 		`=bx-=kc=Bi=tx bx-kcBitx ~bx-kcBitx =bx-=kc=Bi=tx haHODoni`
@@ -472,3 +575,396 @@ func TestCanonicalToSSEsToCanonical(t *testing.T) {
 		t.Errorf("bad BadCanonicalToSSEs\nexpect: %v\nactual: %v\n", expect, actual)
 	}
 }
+
+func TestCanonicalToSSEsFourSyllablesThenUnicode(t *testing.T) {
+	// A word of four syllables leaves room for a fifth, so a Unicode rune that
+	// follows it must start a new SSE rather than find the Sango SSE full.
+	c := `ko_do_ro_so_U+002E ba_ba_ba_ba_U+00AB`
+	sses, err := CanonicalToSSEs(c)
+	if err != nil {
+		t.Fatalf("unexpected error returned from CanonicalToSSEs\nerr = %v", err)
+	}
+	var s strings.Builder
+	for _, sse := range sses {
+		sse.WriteAsCanonicalTo(&s)
+	}
+	expect := `ko_do_ro_so_U+002E ba_ba_ba_ba_U+00AB`
+	if s.String() != expect {
+		t.Errorf("bad CanonicalToSSEs\nexpect: %v\nactual: %v\n", expect, s.String())
+	}
+}
+
+func TestUTF8ToCanonical(t *testing.T) {
+	for _, test := range []struct{ utf8, canonical string }{
+		{"ahön-ndönî", "ha_HO:-Do:ni^"},
+		{"angɛlɛ̂ɛ", "ha_Gx_lx^hx_"},
+		{"âla-mvɛnî", "ha^la_-Vx_ni^"},
+		{"«Tɛrɛ ahûnda kɔ̂bɛ.»", "U+00AB~tx_rx_ ha_Hu^Da_ kc^bx_U+002EU+00BB"},
+		{"TƐRƐ na NGÛru", "=tx_=rx_ na_ ~Gu^ru_"},
+		{"ngbangba ñ ọ", "Qa_Qa_U+0020U+00F1 ho"},
+		{"Paris 2024", "U+0050U+0061U+0072U+0069U+0073U+0020U+0032U+0030U+0032U+0034"},
+	} {
+		if actual := utf8ToCanonical(test.utf8); actual != test.canonical {
+			t.Errorf("utf8ToCanonical(%q)\nexpect: %v\nactual: %v\n", test.utf8, test.canonical, actual)
+		}
+	}
+}
+
+func TestUTF8ToSSEsToUTF8(t *testing.T) {
+	utf8 := "Lo bâa ngbangbo-ôko na âla-mvɛnî ahöñ-ndönî."
+	sses, err := UTF8ToSSEs(utf8)
+	if err != nil {
+		t.Fatalf("unexpected error returned from UTF8ToSSEs\nerr = %v", err)
+	}
+	var s strings.Builder
+	for _, sse := range sses {
+		sse.WriteAsUTF8To(&s)
+	}
+	// expect := utf8, but with the leading syllable in Titlecase.
+	expect := "LO bâa ngbangbo-ôko na âla-mvɛnî ahöñ-ndönî."
+	if s.String() != expect {
+		t.Errorf("bad UTF8ToSSEs\nexpect: %v\nactual: %v\n", expect, s.String())
+	}
+	if _, err := UTF8ToSSEs("𝄞"); err != nil {
+		t.Errorf("unexpected error for a rune outside the Basic Multilingual Plane: %v", err)
+	}
+}
+
+func TestWriteIPA(t *testing.T) {
+	sses, err := UTF8ToSSEs("Ngbâ kɔ̂bɛ na mvɛnî-ahöñ, ạkpa!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		options WriteIPAOptions
+		expect  string
+	}{
+		{AsIPA, "ᵑɡ͡bá kɔ́bɛ̀ nà ᶬvɛ̀ní.àhȭ, ak͡pà!"},
+		{AsIPAChao, "ᵑɡ͡ba˥ kɔ˥bɛ˩ na˩ ᶬvɛ˩ni˥.a˩hõ˧, ak͡pa˩!"},
+		{AsXSAMPA, "Ng_ba_H kO_HbE_L na_L FvE_Lni_H.a_Lho~_M, ak_pa_L!"},
+		{WriteIPAOptions{Tone: ToneNone, ForSpaceUse: " ", ForHyphenUse: "-", ForSyllableBreakUse: "."}, "ᵑɡ͡ba kɔ.bɛ na ᶬvɛ.ni-a.hõ, a.k͡pa!"},
+	} {
+		var s strings.Builder
+		for _, sse := range sses {
+			sse.WriteIPATo(&s, test.options)
+		}
+		if s.String() != test.expect {
+			t.Errorf("bad WriteIPATo(%+v)\nexpect: %v\nactual: %v\n", test.options, test.expect, s.String())
+		}
+	}
+}
+
+func TestPhoneticSyllables(t *testing.T) {
+	sses, err := UTF8ToSSEs("mvɛnî-ạhöñ")
+	if err != nil || len(sses) != 1 {
+		t.Fatal(sses, err)
+	}
+	expect := []PhoneticSyllable{{"ᶬv", "ɛ", "L", false}, {"n", "i", "H", false}, {"", "a", "", true}, {"h", "õ", "M", false}}
+	if actual := sses[0].PhoneticSyllables(false); !slices.Equal(actual, expect) {
+		t.Errorf("bad PhoneticSyllables(false)\nexpect: %v\nactual: %v\n", expect, actual)
+	}
+	expect = []PhoneticSyllable{{"Fv", "E", "L", false}, {"n", "i", "H", false}, {"", "a", "", true}, {"h", "o~", "M", false}}
+	if actual := sses[0].PhoneticSyllables(true); !slices.Equal(actual, expect) {
+		t.Errorf("bad PhoneticSyllables(true)\nexpect: %v\nactual: %v\n", expect, actual)
+	}
+	if actual := SSE(0x0021).PhoneticSyllables(false); actual != nil {
+		t.Errorf("bad PhoneticSyllables of a rune: %v", actual)
+	}
+}
+
+func TestSyllables(t *testing.T) {
+	sses, err := CanonicalToSSEs(" ~kc^-bx_")
+	if err != nil || len(sses) != 1 {
+		t.Fatal(sses, err)
+	}
+	x := sses[0]
+	if x.Kind() != KindSango || x.Prefix() != PrefixCode_Space || x.Shift() != ShiftCode_Title {
+		t.Errorf("bad Kind, Prefix, or Shift of %016X: %v, %04X, %04X", uint64(x), x.Kind(), x.Prefix(), x.Shift())
+	}
+	expect := []Syllable{
+		{InfixCode_None, ConsonantCode_k, VowelCode_c, PitchCode_High},
+		{InfixCode_Hyphen, ConsonantCode_b, VowelCode_x, PitchCode_Low},
+	}
+	if actual := x.Syllables(); !slices.Equal(actual, expect) {
+		t.Errorf("bad Syllables\nexpect: %v\nactual: %v\n", expect, actual)
+	}
+	if r := SSE(0x0021); r.Kind() != KindUnicode || r.Syllables() != nil || r.Prefix() != PrefixCode_None || r.Shift() != ShiftCode_Invisible || r.Melody() != "" {
+		t.Errorf("bad accessors of a rune")
+	}
+	if sses, _ := CanonicalToSSEs("ha_Hu^Da:ba"); sses[0].Melody() != "LHMU" {
+		t.Errorf("bad Melody of %016X: %q", uint64(sses[0]), sses[0].Melody())
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	y, err := NewBuilder().WithPrefix(PrefixCode_Space).WithShift(ShiftCode_Title).
+		Append(Syllable{Consonant: ConsonantCode_k, Vowel: VowelCode_c, Pitch: PitchCode_High}).
+		Append(Syllable{Infix: InfixCode_Hyphen, Consonant: ConsonantCode_b, Vowel: VowelCode_x, Pitch: PitchCode_Low}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sses, _ := CanonicalToSSEs(" ~kc^-bx_")
+	if y != sses[0] {
+		t.Errorf("bad Build: expect %016X, actual %016X", uint64(sses[0]), uint64(y))
+	}
+	b := y.Edit()
+	b.Syllables()[1].Pitch = PitchCode_Mid
+	z, err := b.WithPrefix(PrefixCode_None).WithShift(ShiftCode_lower).Build()
+	var s strings.Builder
+	z.WriteAsUTF8To(&s)
+	if err != nil || s.String() != "kɔ̂-bɛ̈" {
+		t.Errorf("bad Edit: %q, %v", s.String(), err)
+	}
+	if actual := y.Syllables()[1].String(); actual != "-bx_" {
+		t.Errorf("bad Syllable.String: %q", actual)
+	}
+	if k0, k1 := y.Syllables()[0].Key(), y.Syllables()[1].Key(); len(k0) != len(k1) || k0 >= k1 {
+		t.Errorf("bad Syllable.Key: %q, %q", k0, k1)
+	}
+	a := Syllable{Consonant: ConsonantCode_h, Vowel: VowelCode_a}
+	for _, b := range []*Builder{
+		NewBuilder(),
+		NewBuilder().Append(a, a, a, a, a, a),
+		NewBuilder().Append(Syllable{Vowel: VowelCode_a}),
+		NewBuilder().Append(Syllable{Consonant: ConsonantCode_k}),
+		NewBuilder().Append(Syllable{Consonant: ConsonantCode_k, Vowel: VowelCode_a, Pitch: 0x10}),
+		NewBuilder().WithShift(0x8000).Append(a),
+	} {
+		if x, err := b.Build(); err == nil {
+			t.Errorf("expected an error building %v, got %016X", b.Syllables(), uint64(x))
+		}
+	}
+}
+
+func TestWordKeyUnderIsText(t *testing.T) {
+	sses, _ := UTF8ToSSEs("Kɔ̂bɛ 😀")
+	key := []rune(WordKeyUnder(sses, MaskToneless))
+	if len(key) != 4 || key[0] < sangoKeyPlane || key[1] < sangoKeyPlane || key[2] != ' ' || key[3] != '😀' {
+		t.Errorf("WordKeyUnder = %q, expected 2 Sango syllables, a space, and 😀", string(key))
+	}
+	prefix, _ := UTF8ToSSEs("kobe")
+	if !strings.HasPrefix(string(key), WordKeyUnder(prefix, MaskToneless)) {
+		t.Errorf("WordKeyUnder(kobe) is not a prefix of WordKeyUnder(Kɔ̂bɛ 😀)")
+	}
+}
+
+func TestMask(t *testing.T) {
+	word := func(s string) []SSE {
+		sses, err := UTF8ToSSEs(s)
+		if err != nil {
+			t.Fatal(s, err)
+		}
+		return sses
+	}
+	for _, test := range []struct {
+		a, b  string
+		mask  Mask
+		equal bool
+	}{
+		{"kɔ̂bɛ", "kɔ̂bɛ", MaskNone, true},
+		{"kɔ̂bɛ", "KƆ̂BƐ", MaskNone, false},
+		{"kɔ̂bɛ", "KƆ̂BƐ", IgnoreShift, true},
+		{"kɔ̂bɛ", "kobe", IgnoreHeight, false},
+		{"kɔ̂bɛ", "kobe", MaskToneless, true},
+		{"kɔ̂bɛ", "kôbe", MaskHeightless, true},
+		{"lâ-kûî", "lâ kûî", MaskHeightless, false},
+		{"lâ-kûî", "lâ kûî", IgnorePrefix | IgnoreInfix, true},
+		{"ahön", "ahö", MaskToneless, false},
+		{"ahön", "ahö", IgnoreNasality, true},
+		{"lo", "ro", MaskNone, false},
+		{"lo", "ro", MergeLR, true},
+		{"hâ", "â", MaskNone, false},
+		{"hâ", "â", MergeH, true},
+		{"kɔ̂bɛ 😀", "kobe 😀", MaskToneless, true},
+	} {
+		a, b := word(test.a), word(test.b)
+		if key := WordKeyUnder(a, test.mask); !utf8.ValidString(key) {
+			t.Errorf("WordKeyUnder(%q, %v) = %q is not UTF8", test.a, test.mask, key)
+		}
+		if equal := WordKeyUnder(a, test.mask) == WordKeyUnder(b, test.mask); equal != test.equal {
+			t.Errorf("WordKeyUnder(%q, %v) == WordKeyUnder(%q, %v): %v", test.a, test.mask, test.b, test.mask, equal)
+		}
+		if equal := CompareWordsUnder(a, b, test.mask) == 0; equal != test.equal {
+			t.Errorf("CompareWordsUnder(%q, %q, %v) == 0: %v", test.a, test.b, test.mask, equal)
+		}
+		if len(a) == 1 && len(b) == 1 {
+			if equal := a[0].EqualUnder(b[0], test.mask); equal != test.equal {
+				t.Errorf("EqualUnder(%q, %q, %v): %v", test.a, test.b, test.mask, equal)
+			}
+			if equal := a[0].KeyUnder(test.mask) == b[0].KeyUnder(test.mask); equal != test.equal {
+				t.Errorf("KeyUnder(%q) == KeyUnder(%q) under %v: %v", test.a, test.b, test.mask, equal)
+			}
+		}
+	}
+	words := []string{"zo", "kɔ̂bɛ", "kɔbɛ", "!", "a", "Kɔ̂bɛ", "kɔ̈", "mbɛ̂nî", "ngbangbo", "ânî"}
+	slices.SortStableFunc(words, func(a, b string) int { return CompareWordsUnder(word(a), word(b), MaskNone) })
+	expect := []string{"!", "a", "ânî", "mbɛ̂nî", "ngbangbo", "kɔ̈", "kɔbɛ", "kɔ̂bɛ", "Kɔ̂bɛ", "zo"}
+	if !slices.Equal(words, expect) {
+		t.Errorf("bad collation\nexpect: %v\nactual: %v\n", expect, words)
+	}
+}
+
+func TestPattern(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		matches []string
+		others  []string
+	}{
+		{"H L", []string{"kɔ̂bɛ", "hûnda", "ndâ-li"}, []string{"kɔ̂", "kɔbɛ", "kɔ̂bɛ̂", "kɔ̂bɛnî"}},
+		{"* N", []string{"ahön", "in-in"}, []string{"ahö", "kɔ̂bɛ"}},
+		{"* P *", []string{"mângbi", "ngbangbo", "lâ-ndo"}, []string{"kɔ̂bɛ"}},
+		{"kɔ̂ *", []string{"kɔ̂", "kɔ̂bɛ"}, []string{"kɔbɛ", "kôbe"}},
+		{"0 . *", []string{"ahön", "îri"}, []string{"hûnda", "â"}},
+		{"COM", []string{"kä", "tɛ̈"}, []string{"kâ", "kañ"}},
+		{"baL", []string{"ba"}, []string{"bâ", "mba"}},
+	} {
+		p, err := CompilePattern(test.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, words := range []struct {
+			words  []string
+			expect bool
+		}{{test.matches, true}, {test.others, false}} {
+			for _, w := range words.words {
+				sses, _ := UTF8ToSSEs(w)
+				if actual := p.Match(sses); actual != words.expect {
+					t.Errorf("Pattern(%q).Match(%q) = %v", test.pattern, w, actual)
+				}
+			}
+		}
+	}
+	if p := MustCompilePattern("*"); p.Match([]SSE{0x0021}) {
+		t.Errorf("Unicode runes matched a pattern")
+	}
+	for _, pattern := range []string{"x", "bâH", "kaa", "VC"} {
+		if _, err := CompilePattern(pattern); err == nil {
+			t.Errorf("CompilePattern(%q): expected an error", pattern)
+		}
+	}
+}
+
+// Every valid rune round trips through SSEs, at each offset within an SSE.
+// The canonical format is checked for every 61st rune, since it is much slower.
+func TestRoundTripAllRunes(t *testing.T) {
+	step := rune(1)
+	if testing.Short() {
+		step = 61
+	}
+	var u, c strings.Builder
+	for r := rune(1); r <= unicode.MaxRune; r += step {
+		if utf16.IsSurrogate(r) {
+			continue
+		}
+		// The bangs put the runes at each of the UTF16 units packed into an SSE.
+		offset := int(r % 4)
+		s := strings.Repeat("!", offset) + string(r)
+		sses, err := UTF8ToSSEs(s)
+		if err != nil {
+			t.Fatalf("UTF8ToSSEs(%q): %v", s, err)
+		}
+		u.Reset()
+		c.Reset()
+		for _, x := range sses {
+			x.WriteAsUTF8To(&u)
+			x.WriteAsCanonicalTo(&c)
+		}
+		if expect := norm.NFC.String(s); u.String() != expect {
+			t.Fatalf("bad UTF8 round trip of %U at offset %v: %q, expected %q", r, offset, u.String(), expect)
+		}
+		if parsed, err := CanonicalToSSEs(c.String()); err != nil || !slices.Equal(parsed, sses) {
+			t.Fatalf("bad canonical round trip of %U at offset %v: %q, %v", r, offset, c.String(), err)
+		}
+	}
+}
+
+func TestUTF8ToSSEsOutsideBMP(t *testing.T) {
+	for _, s := range []string{"😀", "mbï yeke 😀!", "𝄞𝄞𝄞𝄞𝄞", "a😀b😀c😀", "🇨🇫 ködörösêse tî bêafrîka"} {
+		sses, err := UTF8ToSSEs(s)
+		if err != nil {
+			t.Errorf("UTF8ToSSEs(%q): %v", s, err)
+		}
+		var actual strings.Builder
+		for _, x := range sses {
+			x.WriteAsUTF8To(&actual)
+		}
+		if actual.String() != s {
+			t.Errorf("UTF8ToSSEs(%q) round trip: %q", s, actual.String())
+		}
+	}
+	for _, c := range []string{"U+D83D", "U+DE00", "U+D83DU+0021", "U+D83Dba_", "U+D83DU+D83DU+DE00"} {
+		if sses, err := CanonicalToSSEs(c); err == nil {
+			t.Errorf("CanonicalToSSEs(%q) = %v: expected an error for a lone surrogate", c, sses)
+		}
+	}
+}
+
+func TestEncodings(t *testing.T) {
+	word, _ := CanonicalToSSEs(" kc^bx_")
+	runes, _ := CanonicalToSSEs("U+65E5U+0021")
+	for _, x := range []SSE{word[0], runes[0], 0} {
+		text, _ := x.MarshalText()
+		var y SSE
+		if err := y.UnmarshalText(text); err != nil || y != x {
+			t.Errorf("text %q of %016X unmarshals to %016X, %v", text, uint64(x), uint64(y), err)
+		}
+		data, _ := json.Marshal(x)
+		y = 0
+		if err := json.Unmarshal(data, &y); err != nil || y != x {
+			t.Errorf("JSON %s of %016X unmarshals to %016X, %v", data, uint64(x), uint64(y), err)
+		}
+		data, _ = x.MarshalBinary()
+		y = 0
+		if err := y.UnmarshalBinary(data); err != nil || y != x {
+			t.Errorf("binary %x of %016X unmarshals to %016X, %v", data, uint64(x), uint64(y), err)
+		}
+		v, _ := x.Value()
+		y = 0
+		if err := y.Scan(v); err != nil || y != x {
+			t.Errorf("value %v of %016X scans to %016X, %v", v, uint64(x), uint64(y), err)
+		}
+	}
+
+	data, _ := json.Marshal(struct {
+		Canonical SSE
+		UTF8      UTF8
+	}{word[0], UTF8(word[0])})
+	if expect := `{"Canonical":" kc^bx_","UTF8":" kɔ̂bɛ"}`; string(data) != expect {
+		t.Errorf("bad JSON\nexpect: %s\nactual: %s", expect, data)
+	}
+	var u UTF8
+	if err := json.Unmarshal([]byte(`"kɔ̂bɛ"`), &u); err != nil || SSE(u).Syllables()[0].Pitch != PitchCode_High {
+		t.Errorf("bad UTF8 unmarshal %016X, %v", uint64(u), err)
+	}
+
+	var y SSE
+	for _, bad := range []string{`"kc^bx_?"`, `"kc^ bx_"`, `42`} {
+		if err := json.Unmarshal([]byte(bad), &y); err == nil {
+			t.Errorf("JSON %s unmarshals without error", bad)
+		}
+	}
+	if err := y.UnmarshalBinary([]byte{1, 2, 3}); err == nil {
+		t.Errorf("3 bytes unmarshal without error")
+	}
+	if err := y.Scan(3.5); err == nil {
+		t.Errorf("float scans without error")
+	}
+	if err := y.Scan("kc^bx_"); err != nil || y.Syllables()[1].Vowel != VowelCode_x {
+		t.Errorf("bad scan of Canonical text %016X, %v", uint64(y), err)
+	}
+
+	for _, test := range []struct{ format, expect string }{
+		{"%s", " kɔ̂bɛ"},
+		{"%v", " kɔ̂bɛ"},
+		{"[%8s]", "[   kɔ̂bɛ]"},
+		{"%q", `" kc^bx_"`},
+		{"%x", "d36f115000000000"},
+		{"%016X", "D36F115000000000"},
+		{"%#v", "sse.SSE(0xD36F115000000000)"},
+	} {
+		if actual := fmt.Sprintf(test.format, word[0]); actual != test.expect {
+			t.Errorf("bad Sprintf(%q)\nexpect: %q\nactual: %q", test.format, test.expect, actual)
+		}
+	}
+}
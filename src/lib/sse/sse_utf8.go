@@ -0,0 +1,226 @@
+// SSE UTF8 Parser
+//
+// Parses Sango orthography (as written by WriteAsUTF8To or WriteAsLemmaTo)
+// into SSEs, by first rewriting each Sango word into Canonical format.
+//
+// Unmarked vowels have low pitch and a dot below marks unknown pitch.
+// Vowels of unknown height are written ə (e or ɛ) and ø (o or ɔ).
+// A syllable-final n (or ñ) nasalizes the preceding vowel unless it begins
+// the next syllable. Words that cannot be parsed as Sango syllables, as well
+// as all punctuation, digits, and whitespace, are stored as Unicode runes.
+
+package sse
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func utf8ToSSEs(s string) ([]SSE, error) {
+	return canonicalToSSEs(utf8ToCanonical(s))
+}
+
+func utf8ToCanonical(s string) string {
+	var c strings.Builder
+	rr := []rune(norm.NFD.String(s))
+	pendingSpace := false
+	writeLiteral := func(lit []rune) {
+		for _, unit := range utf16.Encode([]rune(norm.NFC.String(string(lit)))) {
+			c.WriteString(fmt.Sprintf("U+%04X", unit)) // a surrogate pair outside the BMP
+		}
+	}
+	flushSpace := func() {
+		if pendingSpace {
+			c.WriteString("U+0020")
+			pendingSpace = false
+		}
+	}
+	for i := 0; i < len(rr); {
+		if rr[i] == ' ' {
+			flushSpace()
+			pendingSpace = true
+			i++
+			continue
+		}
+		j := endOfWord(rr, i)
+		if j == i {
+			// A rune other than a letter, with the marks that compose with it.
+			for j++; j < len(rr) && unicode.Is(unicode.M, rr[j]); j++ {
+			}
+			flushSpace()
+			writeLiteral(rr[i:j])
+			i = j
+			continue
+		}
+		if syllables, ok := wordToCanonical(rr[i:j]); ok {
+			if pendingSpace {
+				c.WriteString(" ")
+				pendingSpace = false
+			}
+			c.WriteString(syllables)
+		} else {
+			flushSpace()
+			writeLiteral(rr[i:j])
+		}
+		i = j
+	}
+	flushSpace()
+	return c.String()
+}
+
+// Returns the index just past the letters, marks, and inner hyphens starting at rr[i].
+func endOfWord(rr []rune, i int) int {
+	j := i
+	for j < len(rr) {
+		r := rr[j]
+		if unicode.IsLetter(r) || j > i && unicode.Is(unicode.M, r) {
+			j++
+		} else if r == '-' && j > i && j+1 < len(rr) && unicode.IsLetter(rr[j+1]) {
+			j++
+		} else {
+			break
+		}
+	}
+	return j
+}
+
+var onsets = []struct {
+	utf8, canonical string
+}{
+	{"ngb", "Q"}, {"mb", "B"}, {"mp", "P"}, {"mv", "V"}, {"nd", "D"}, {"ng", "G"},
+	{"ny", "Y"}, {"nz", "Z"}, {"gb", "q"}, {"kp", "K"}, {"b", "b"}, {"d", "d"},
+	{"f", "f"}, {"g", "g"}, {"h", "H"}, {"k", "k"}, {"l", "l"}, {"m", "m"},
+	{"n", "n"}, {"p", "p"}, {"r", "r"}, {"s", "s"}, {"t", "t"}, {"v", "v"},
+	{"w", "w"}, {"y", "y"}, {"z", "z"},
+}
+
+var vowels = map[rune]string{
+	'a': "a", 'e': "e", 'i': "i", 'o': "o", 'u': "u", 'ɛ': "x", 'ɔ': "c", 'ə': "X", 'ø': "C",
+}
+
+var nasalVowels = map[string]string{
+	"a": "A", "e": "E", "i": "I", "o": "O", "u": "U", "x": "E", "c": "O", "X": "E", "C": "O",
+}
+
+// Returns the onset at lower[i:] (and its length) if followed by a vowel.
+func parseOnset(lower []rune, i int) (string, int) {
+	for _, onset := range onsets {
+		n := len([]rune(onset.utf8))
+		if i+n < len(lower) && string(lower[i:i+n]) == onset.utf8 {
+			if _, isVowel := vowels[lower[i+n]]; isVowel {
+				return onset.canonical, n
+			}
+		}
+	}
+	return "", 0
+}
+
+// Rewrites one word (in NFD) into Canonical syllables, if it is valid Sango.
+func wordToCanonical(word []rune) (string, bool) {
+	lower := make([]rune, len(word))
+	for k, r := range word {
+		lower[k] = unicode.ToLower(r)
+	}
+	var c strings.Builder
+	first := true
+	for i := 0; i < len(word); {
+		start := i
+		infix := ""
+		if word[i] == '-' {
+			if first {
+				return "", false
+			}
+			infix = "-"
+			i++
+			start = i
+		}
+		consonant, n := parseOnset(lower, i)
+		if n == 0 {
+			consonant = "h"
+		}
+		i += n
+		if i >= len(word) {
+			return "", false
+		}
+		vowel, isVowel := vowels[lower[i]]
+		if !isVowel {
+			return "", false
+		}
+		i++
+		pitch := "_"
+		for marks := 0; i < len(word) && unicode.Is(unicode.Mn, word[i]); marks++ {
+			if marks > 0 {
+				return "", false // a vowel has at most one pitch
+			}
+			switch word[i] {
+			case '\u0302':
+				pitch = "^"
+			case '\u0308':
+				pitch = ":"
+			case '\u0323':
+				pitch = ""
+			default:
+				return "", false
+			}
+			i++
+		}
+		if i < len(word) && lower[i] == 'n' {
+			if i+1 < len(word) && word[i+1] == '\u0303' {
+				vowel = nasalVowels[vowel]
+				i += 2
+			} else if _, m := parseOnset(lower, i); m == 0 {
+				vowel = nasalVowels[vowel]
+				i++
+			}
+		}
+		shift, ok := shiftOf(word, start, i, first)
+		if !ok {
+			return "", false
+		}
+		c.WriteString(infix + shift + consonant + vowel + pitch)
+		first = false
+	}
+	return c.String(), true
+}
+
+// Returns the Canonical shift of the syllable word[start:end].
+func shiftOf(word []rune, start, end int, first bool) (string, bool) {
+	var letters []rune
+	for _, r := range word[start:end] {
+		if unicode.IsLetter(r) {
+			letters = append(letters, r)
+		}
+	}
+	allLower, allUpper, titled := true, true, unicode.IsUpper(letters[0])
+	for k, r := range letters {
+		allLower = allLower && !unicode.IsUpper(r)
+		allUpper = allUpper && !unicode.IsLower(r)
+		if k > 0 {
+			titled = titled && !unicode.IsUpper(r)
+		}
+	}
+	hasRest, restIsUpper := false, false
+	for _, r := range word[end:] {
+		if unicode.IsLetter(r) {
+			hasRest, restIsUpper = true, unicode.IsUpper(r)
+			break
+		}
+	}
+	// Title shift is written with the whole first syllable in title case,
+	// but a word with only its first letter capitalized is also Title.
+	switch {
+	case allLower:
+		return "", true
+	case !first && allUpper:
+		return "=", true
+	case first && allUpper && (restIsUpper || !hasRest && len(letters) > 1):
+		return "=", true
+	case first && (allUpper || titled):
+		return "~", true
+	}
+	return "", false
+}
@@ -0,0 +1,251 @@
+// SSE Word Accessors
+//
+// Typed access to the components of an SSE, so that callers need not know its
+// bit layout: its kind, the prefix and shift of a Sango word, and the infix,
+// consonant, vowel, and pitch of each of its syllables.
+//
+// A Builder assembles a Sango word from typed syllables, and checks that the
+// result is a valid SSE, e.g.
+//
+//	x, err := sse.NewBuilder().WithPrefix(sse.PrefixCode_Space).
+//		Append(sse.Syllable{Consonant: sse.ConsonantCode_k, Vowel: sse.VowelCode_c, Pitch: sse.PitchCode_High}).
+//		Append(sse.Syllable{Consonant: sse.ConsonantCode_b, Vowel: sse.VowelCode_x, Pitch: sse.PitchCode_Low}).
+//		Build() // " kɔ̂bɛ"
+
+package sse
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Kind int
+
+const (
+	KindUnicode Kind = iota // up to 4 Unicode runes
+	KindSango               // one Sango word of up to 5 syllables
+)
+
+// The maximum number of syllables in a Sango word.
+const MaxSyllables = 5
+
+// One Sango syllable. The zero Syllable is not valid, since every syllable has a
+// consonant (perhaps the unaspirated ConsonantCode_h) and a vowel.
+type Syllable struct {
+	Infix     InfixCode // InfixCode_Hyphen if preceded by a hyphen within the word
+	Consonant ConsonantCode
+	Vowel     VowelCode
+	Pitch     PitchCode
+}
+
+func (sse SSE) Kind() Kind {
+	return kindOf(uint64(sse))
+}
+
+// Returns the syllables of a Sango word, or nil for Unicode runes.
+func (sse SSE) Syllables() []Syllable {
+	return syllablesOf(uint64(sse))
+}
+
+// Returns whether a Sango word follows a space, or PrefixCode_None for Unicode runes.
+func (sse SSE) Prefix() PrefixCode {
+	if kindOf(uint64(sse)) != KindSango {
+		return PrefixCode_None
+	}
+	return getPrefixCode(globalCodeOf(uint64(sse)))
+}
+
+// Returns the case of a Sango word, or ShiftCode_Invisible for Unicode runes.
+func (sse SSE) Shift() ShiftCode {
+	if kindOf(uint64(sse)) != KindSango {
+		return ShiftCode_Invisible
+	}
+	return getShiftCode(globalCodeOf(uint64(sse)))
+}
+
+// Returns the tone melody of a Sango word, with one of H (high), M (mid), L (low),
+// or U (unknown) per syllable, e.g. kɔ̂bɛ -> HL, or "" for Unicode runes.
+func (sse SSE) Melody() string {
+	return melodyOf(uint64(sse))
+}
+
+// Returns a Builder with the prefix, shift, and syllables of a Sango word, e.g.
+// to change the pitch of one of its syllables.
+func (sse SSE) Edit() *Builder {
+	return &Builder{prefix: sse.Prefix(), shift: sse.Shift(), syllables: sse.Syllables()}
+}
+
+// Writes a syllable in canonical format (without prefix or shift), e.g. -kc^.
+func (s Syllable) String() string {
+	return s.string()
+}
+
+// Returns a key of the syllable (without prefix or shift), e.g. to index words by
+// their syllables. Keys of syllables have the same length and sort by infix, then
+// consonant, then vowel, then pitch.
+func (s Syllable) Key() string {
+	return s.key()
+}
+
+// Assembles a Sango word one syllable at a time.
+type Builder struct {
+	prefix    PrefixCode
+	shift     ShiftCode
+	syllables []Syllable
+}
+
+// Returns a Builder of a lowercase word without a prefix.
+func NewBuilder() *Builder {
+	return &Builder{prefix: PrefixCode_None, shift: ShiftCode_lower}
+}
+
+func (b *Builder) WithPrefix(prefix PrefixCode) *Builder {
+	b.prefix = prefix
+	return b
+}
+
+func (b *Builder) WithShift(shift ShiftCode) *Builder {
+	b.shift = shift
+	return b
+}
+
+func (b *Builder) Append(syllables ...Syllable) *Builder {
+	b.syllables = append(b.syllables, syllables...)
+	return b
+}
+
+// Returns the syllables appended so far, which the caller may modify in place.
+func (b *Builder) Syllables() []Syllable {
+	return b.syllables
+}
+
+// Returns the word, or an error if it has no syllables, more than MaxSyllables,
+// or an invalid component.
+func (b *Builder) Build() (SSE, error) {
+	return b.build()
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+var melodyLetters = [...]string{
+	PitchCode_Unknown: "U",
+	PitchCode_Low:     "L",
+	PitchCode_Mid:     "M",
+	PitchCode_High:    "H",
+}
+
+func melodyOf(b uint64) string {
+	var s strings.Builder
+	for _, syllable := range syllablesOf(b) {
+		s.WriteString(melodyLetters[syllable.Pitch])
+	}
+	return s.String()
+}
+
+// The bit layout of an SSE. A Unicode SSE holds up to unicodeCodes UTF-16 code
+// units of unicodeBits each. A Sango SSE holds the global bits (IsSango, prefix,
+// and shift) of its codes, then up to MaxSyllables syllables (infix, consonant,
+// vowel, and pitch) of syllableBits each. Both are left aligned.
+const (
+	unicodeBits  = 16
+	unicodeCodes = 4
+	unicodeMask  = 1<<unicodeBits - 1
+	globalBits   = 4
+	syllableBits = 12
+	syllableMask = 1<<syllableBits - 1
+)
+
+func kindOf(b uint64) Kind {
+	if globalCodeOf(b)&IsSango_MASK == 0 {
+		return KindUnicode
+	}
+	return KindSango
+}
+
+// Returns the kth UTF-16 code unit of a Unicode SSE.
+func unicodeCodeAt(b uint64, k int) uint16 {
+	return uint16(b >> (64 - unicodeBits*(k+1)))
+}
+
+// Returns the global bits of a Sango SSE, in place within a 16-bit code.
+func globalCodeOf(b uint64) uint16 {
+	return uint16(b>>(64-globalBits)) << syllableBits
+}
+
+// Returns the syllable bits of the kth code of a Sango SSE.
+func syllableCodeAt(b uint64, k int) uint16 {
+	return uint16(b>>(64-globalBits-syllableBits*(k+1))) & syllableMask
+}
+
+// Returns the SSE bits of the global bits of a code.
+func globalBitsOf(code uint16) uint64 {
+	return uint64(code>>syllableBits) << (64 - globalBits)
+}
+
+// Returns the SSE bits of the syllable bits of a code, as the kth syllable.
+func syllableBitsAt(code uint16, k int) uint64 {
+	return uint64(code&syllableMask) << (64 - globalBits - syllableBits*(k+1))
+}
+
+func syllablesOf(b uint64) []Syllable {
+	if kindOf(b) != KindSango {
+		return nil
+	}
+	var syllables []Syllable
+	for _, code := range syllableCodes(b) {
+		syllables = append(syllables, Syllable{
+			Infix:     getInfixCode(code),
+			Consonant: getConsonantCode(code),
+			Vowel:     getVowelCode(code),
+			Pitch:     getPitchCode(code),
+		})
+	}
+	return syllables
+}
+
+// Returns the 12 syllable bits of the 16-bit code.
+func (s Syllable) code() uint16 {
+	return uint16(s.Infix) | uint16(s.Consonant) | uint16(s.Vowel) | uint16(s.Pitch)
+}
+
+func (s Syllable) key() string {
+	return string(sangoKeyPlane + rune(s.code()))
+}
+
+func (s Syllable) string() string {
+	return canonicalFromSangoCodeValue(IsSango_MASK | uint16(ShiftCode_lower) | s.code())
+}
+
+func (b *Builder) build() (SSE, error) {
+	n := len(b.syllables)
+	if n == 0 {
+		return 0, fmt.Errorf("cannot build a Sango word without syllables")
+	}
+	if n > MaxSyllables {
+		return 0, fmt.Errorf("cannot build a Sango word of %v syllables: at most %v", n, MaxSyllables)
+	}
+	global := IsSango_MASK | uint16(b.prefix) | uint16(b.shift)
+	if !hasValidPrefix(global) || uint16(b.prefix)&^PrefixCode_MASK != 0 {
+		return 0, fmt.Errorf("invalid prefix code 0x%04X", uint16(b.prefix))
+	}
+	if !hasValidShift(global) || uint16(b.shift)&^ShiftCode_MASK != 0 {
+		return 0, fmt.Errorf("invalid shift code 0x%04X", uint16(b.shift))
+	}
+	word := globalBitsOf(global)
+	for k, s := range b.syllables {
+		code := s.code()
+		switch {
+		case uint16(s.Infix)&^InfixCode_MASK != 0 || !hasValidInfix(code):
+			return 0, fmt.Errorf("syllable %v: invalid infix code 0x%04X", k, uint16(s.Infix))
+		case uint16(s.Consonant)&^ConsonantCode_MASK != 0 || !hasValidConsonant(code):
+			return 0, fmt.Errorf("syllable %v: invalid consonant code 0x%04X", k, uint16(s.Consonant))
+		case uint16(s.Vowel)&^VowelCode_MASK != 0 || !hasValidVowel(code):
+			return 0, fmt.Errorf("syllable %v: invalid vowel code 0x%04X", k, uint16(s.Vowel))
+		case uint16(s.Pitch)&^PitchCode_MASK != 0 || !hasValidPitch(code):
+			return 0, fmt.Errorf("syllable %v: invalid pitch code 0x%04X", k, uint16(s.Pitch))
+		}
+		word |= syllableBitsAt(code, k)
+	}
+	return SSE(word), nil
+}
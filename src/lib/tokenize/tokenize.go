@@ -10,6 +10,7 @@ import (
 	"log"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	cuckoo "github.com/panmari/cuckoofilter"
 	"golang.org/x/text/unicode/norm"
@@ -65,9 +66,41 @@ func ClassifySango(in io.Reader) []Lemma {
 	return classify(TokenizeSango(in))
 }
 
+// Returns the UTF8 (NFC) text of each token of TokenizeSango, as written in the
+// input (without the ASCII substitutions made for tokenizing).
+func TokenTexts(in io.Reader) []string {
+	return tokenTexts(in)
+}
+
 //////////////////////////////////////////////////////////////////////////////
 // IMPLEMENTATION
 
+func tokenTexts(in io.Reader) []string {
+	b, err := io.ReadAll(norm.NFKD.Reader(in))
+	if err != nil {
+		panic(err)
+	}
+	original := string(b)
+	s, tokens := TokenizeSango(strings.NewReader(original))
+
+	// TokenizeSango substitutes one rune for another, so the offset of each
+	// rune in its string maps to the offset of the same rune in the original.
+	offsets := make(map[int]int, len(*s)+1)
+	k := 0
+	for j := range original {
+		offsets[k] = j
+		_, size := utf8.DecodeRuneInString((*s)[k:])
+		k += size
+	}
+	offsets[len(*s)] = len(original)
+
+	texts := make([]string, len(tokens))
+	for i, token := range tokens {
+		texts[i] = norm.NFC.String(original[offsets[token.Begin]:offsets[token.End]])
+	}
+	return texts
+}
+
 var sangoTokenizerRegexps = []*regexp.Regexp{
 	regexp.MustCompile(`\p{Z}+`),                  // whitespace
 	regexp.MustCompile(`\p{Nd}+(?:[.,]\p{Nd}*)*`), // numbers
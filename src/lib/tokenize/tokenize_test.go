@@ -178,3 +178,11 @@ func TestClassifySango(t *testing.T) {
 		{Token{103, 104, 2}, ".", ".", "PUNC", "other"},
 	})
 }
+
+func TestTokenTexts(t *testing.T) {
+	actually := TokenTexts(strings.NewReader("Kɔ̂bɛ, café taxi CFA."))
+	expected := "[Kɔ̂bɛ ,   café   taxi   CFA .]"
+	if fmt.Sprint(actually) != expected {
+		t.Errorf("actually = %v\nexpected = %v", actually, expected)
+	}
+}
@@ -2,25 +2,60 @@ package transliterate
 
 import (
 	"bufio"
+	"fmt"
 	"log"
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/zokwezo/sango/src/lib/sse"
 )
 
 func Init(rootCmd *cobra.Command) {
+	transliterateCmd.Flags().StringSliceVar(&fromFlagValue, "from", []string{"standard"}, "Orthography profiles of stdin, applied in order (see --list).")
+	transliterateCmd.Flags().StringSliceVar(&toFlagValue, "to", []string{"standard"}, "Orthography profiles of stdout, applied in order (see --list).")
+	transliterateCmd.Flags().BoolVar(&listFlagValue, "list", false, "Lists the orthography profiles.")
 	rootCmd.AddCommand(transliterateCmd)
 	transliterateCmd.AddCommand(encodeCmd)
 	transliterateCmd.AddCommand(decodeCmd)
 	transliterateCmd.AddCommand(normalizeCmd)
 	transliterateCmd.AddCommand(unnormalizeCmd)
+	ipaCmd.Flags().BoolVar(&xsampaFlagValue, "xsampa", false, "Writes X-SAMPA instead of IPA.")
+	ipaCmd.Flags().StringVar(&toneFlagValue, "tone", "diacritics", "Writes tone as diacritics, chao (tone letters), or none.")
+	ipaCmd.Flags().StringVar(&syllableBreakFlagValue, "syllable_break", "", "Writes this between the syllables of each word, e.g. \".\".")
+	transliterateCmd.AddCommand(ipaCmd)
 }
 
 var (
+	fromFlagValue          []string
+	toFlagValue            []string
+	listFlagValue          bool
+	xsampaFlagValue        bool
+	toneFlagValue          string
+	syllableBreakFlagValue string
+
 	transliterateCmd = &cobra.Command{
 		Use:   "transliterate",
-		Short: "A CLI to transliterate Sango between UTF8 and ASCII",
+		Short: "A CLI to transliterate Sango between UTF8 and ASCII, or between orthographies with --from and --to",
 		Long:  "https://github.com/zokwezo/sango/blob/main/src/lib/transliterate/README.md",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if listFlagValue {
+				for _, name := range ProfileNames() {
+					p, _ := LookupProfile(name)
+					fmt.Printf("%-10s %s\n", name, p.Description)
+				}
+				return
+			}
+			if !cmd.Flags().Changed("from") && !cmd.Flags().Changed("to") {
+				cmd.Help()
+				return
+			}
+			in := bufio.NewReader(os.Stdin)
+			out := bufio.NewWriter(os.Stdout)
+			if err := Convert(out, in, fromFlagValue, toFlagValue); err != nil {
+				log.Fatal(err)
+			}
+		},
 	}
 
 	encodeCmd = &cobra.Command{
@@ -74,4 +109,28 @@ var (
 			out.Flush()
 		},
 	}
+
+	ipaCmd = &cobra.Command{
+		Use:   "ipa",
+		Short: "Read from stdin, transcribe UTF8 Sango into IPA (or X-SAMPA), then write to stdout",
+		Run: func(cmd *cobra.Command, args []string) {
+			options := sse.WriteIPAOptions{XSAMPA: xsampaFlagValue, ForSpaceUse: " ", ForHyphenUse: ".", ForSyllableBreakUse: syllableBreakFlagValue}
+			switch toneFlagValue {
+			case "diacritics":
+				options.Tone = sse.ToneDiacritics
+			case "chao":
+				options.Tone = sse.ToneChao
+			case "none":
+				options.Tone = sse.ToneNone
+			default:
+				log.Fatalf("Unknown --tone %q: expected diacritics, chao, or none", toneFlagValue)
+			}
+			in := bufio.NewReader(os.Stdin)
+			out := bufio.NewWriter(os.Stdout)
+			if err := IPA(out, in, options); err != nil {
+				log.Fatal(err)
+			}
+			out.Flush()
+		},
+	}
 )
@@ -0,0 +1,189 @@
+// Orthography Profiles
+//
+// A profile describes an alternate spelling of Sango as two tables of rewrite
+// rules, one into the standard orthography and one out of it. Text is converted
+// from one list of profiles to another by applying the ToStandard tables of the
+// first list, then the FromStandard tables of the second, each in the order given.
+// A profile with FromStandard rules but no ToStandard rules drops what it cannot
+// convert back (e.g. tone marks), so text cannot be converted from it.
+//
+// Rules are applied to NFD text, so that a rule can rewrite a combining diacritic
+// of any vowel (e.g. U+0301 -> U+0302 for acute -> circumflex). At each position
+// the first matching rule of a table applies, so longer rules must come first.
+// Rules with letters also apply to their title and upper case forms.
+
+package transliterate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type Rule struct {
+	From, To string
+}
+
+type Profile struct {
+	Name         string
+	Description  string
+	ToStandard   []Rule
+	FromStandard []Rule
+}
+
+// Adds a profile (or replaces the one with the same name) for use by Convert.
+func RegisterProfile(p Profile) {
+	profiles[p.Name] = p
+}
+
+// Returns the names of all registered profiles, in sorted order.
+func ProfileNames() []string {
+	return profileNames()
+}
+
+// Returns the registered profile with this name.
+func LookupProfile(name string) (Profile, bool) {
+	p, ok := profiles[name]
+	return p, ok
+}
+
+// Rewrites text from the spelling of the from profiles into that of the to profiles.
+func ConvertText(text string, from, to []string) (string, error) {
+	return convertText(text, from, to)
+}
+
+// Reads text in the spelling of the from profiles, then writes it in that of the to profiles.
+func Convert(out *bufio.Writer, in *bufio.Reader, from, to []string) error {
+	defer out.Flush()
+	b, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	s, err := convertText(string(b), from, to)
+	if err != nil {
+		return err
+	}
+	_, err = out.WriteString(s)
+	return err
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+var profiles = map[string]Profile{
+	"standard": {
+		Name:        "standard",
+		Description: "standard orthography: ɛ and ɔ, with â (high), ä (mid), and a (low)",
+	},
+	"french": {
+		Name:        "french",
+		Description: "French-influenced spelling: ou for u (or w before a vowel), é for e, è for ɛ, o for ɔ, gn for ny",
+		ToStandard: []Rule{
+			{"oué", "we"},
+			{"ouè", "wɛ"},
+			{"oua", "wa"},
+			{"oue", "we"},
+			{"ouɛ", "wɛ"},
+			{"oui", "wi"},
+			{"ouo", "wo"},
+			{"ouɔ", "wɔ"},
+			{"ou", "u"},
+			{"gn", "ny"},
+			{"é", "e"},
+			{"è", "ɛ"},
+		},
+		FromStandard: []Rule{
+			{"ny", "gn"},
+			{"w", "ou"},
+			{"u", "ou"},
+			{"e", "é"},
+			{"ɛ", "è"},
+			{"ɔ", "o"},
+		},
+	},
+	"acute": {
+		Name:         "acute",
+		Description:  "acute accent for high tone: á for â",
+		ToStandard:   []Rule{{"\u0301", "\u0302"}},
+		FromStandard: []Rule{{"\u0302", "\u0301"}},
+	},
+	"grave": {
+		Name:         "grave",
+		Description:  "grave accent for high tone: à for â",
+		ToStandard:   []Rule{{"\u0300", "\u0302"}},
+		FromStandard: []Rule{{"\u0302", "\u0300"}},
+	},
+	"toneless": {
+		Name:         "toneless",
+		Description:  "no tone marks (output only: see sango restore for restoring them)",
+		FromStandard: []Rule{{"\u0302", ""}, {"\u0308", ""}, {"\u0323", ""}},
+	},
+}
+
+func profileNames() []string {
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func convertText(text string, from, to []string) (string, error) {
+	var replacers []*strings.Replacer
+	for _, names := range []struct {
+		names      []string
+		toStandard bool
+	}{{from, true}, {to, false}} {
+		for _, name := range names.names {
+			p, ok := profiles[name]
+			if !ok {
+				return "", fmt.Errorf("unknown orthography profile %q: expected one of %v", name, strings.Join(profileNames(), ", "))
+			}
+			rules := p.FromStandard
+			if names.toStandard {
+				if len(p.ToStandard) == 0 && len(p.FromStandard) > 0 {
+					return "", fmt.Errorf("cannot convert from orthography profile %q, which cannot be converted back to the standard one", name)
+				}
+				rules = p.ToStandard
+			}
+			replacers = append(replacers, replacerOf(rules))
+		}
+	}
+	text = norm.NFD.String(text)
+	for _, r := range replacers {
+		text = r.Replace(text)
+	}
+	return norm.NFC.String(text), nil
+}
+
+// Returns a replacer for the rules, each followed by its title and upper case forms.
+func replacerOf(rules []Rule) *strings.Replacer {
+	var oldnew []string
+	for _, rule := range rules {
+		from, to := norm.NFD.String(rule.From), norm.NFD.String(rule.To)
+		oldnew = append(oldnew, from, to)
+		if title := titleOf(from); title != from {
+			oldnew = append(oldnew, title, titleOf(to))
+		}
+		if upper := strings.ToUpper(from); upper != from && upper != titleOf(from) {
+			oldnew = append(oldnew, upper, strings.ToUpper(to))
+		}
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+// Returns s with its first letter in upper case, e.g. gn -> Gn.
+func titleOf(s string) string {
+	if s == "" {
+		return s
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
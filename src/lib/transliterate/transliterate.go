@@ -4,10 +4,31 @@ import (
 	"bufio"
 	"bytes"
 	"io"
+	"strings"
 
+	"github.com/zokwezo/sango/src/lib/sse"
 	"golang.org/x/text/unicode/norm"
 )
 
+// Writes the IPA or X-SAMPA transcription of UTF8 Sango text (see sse.WriteIPAOptions).
+// Non-Sango words and punctuation are copied as is.
+func IPA(out *bufio.Writer, in *bufio.Reader, options sse.WriteIPAOptions) error {
+	defer out.Flush()
+	b, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	sses, err := sse.UTF8ToSSEs(string(b))
+	var s strings.Builder
+	for _, x := range sses {
+		x.WriteIPATo(&s, options)
+	}
+	if _, werr := out.WriteString(s.String()); err == nil {
+		err = werr
+	}
+	return err
+}
+
 func Normalize(out *bufio.Writer, in *bufio.Reader) error {
 	defer out.Flush()
 	b, err := io.ReadAll(norm.NFC.Reader(in))
@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"bytes"
 	"testing"
+
+	"github.com/zokwezo/sango/src/lib/sse"
 )
 
 func fromString(in string, transliterate func(*bufio.Writer, *bufio.Reader) error) string {
@@ -31,3 +33,38 @@ func TestTransliterate(t *testing.T) {
 		t.Errorf("ASCII expect = %s", asciiExpect)
 	}
 }
+
+func TestIPA(t *testing.T) {
+	ipa := func(out *bufio.Writer, in *bufio.Reader) error { return IPA(out, in, sse.AsXSAMPA) }
+	if actual := fromString("Kɔ̂bɛ tî ngûru.", ipa); actual != "kO_HbE_L ti_H Ngu_Hru_L." {
+		t.Errorf("X-SAMPA actual = %s", actual)
+	}
+}
+
+func TestConvertText(t *testing.T) {
+	for _, test := range []struct {
+		from, to       []string
+		text, expected string
+	}{
+		{[]string{"french"}, nil, "Gbagba, ngou na yé sô agné.", "Gbagba, ngu na ye sô anye."},
+		{[]string{"french"}, nil, "Mbè OUALI, ouéné", "Mbɛ WALI, wene"},
+		{nil, []string{"french"}, "Mbɛ wali", "Mbè ouali"},
+		{[]string{"french", "acute"}, nil, "Mbi yé ti tené sángó.", "Mbi ye ti tene sângô."},
+		{[]string{"grave"}, []string{"standard"}, "Kɔ̀bɛ tì ngùru", "Kɔ̂bɛ tî ngûru"},
+		{nil, []string{"toneless"}, "Kɔ̂bɛ tî ngûru, Ɛ̈", "Kɔbɛ ti nguru, Ɛ"},
+		{nil, []string{"toneless", "french"}, "Kɔ̂bɛ tî ngûru nyɔ̂ɔ̈", "Kobè ti ngourou gnoo"},
+		{[]string{"standard"}, []string{"acute"}, "Kɔ̂bɛ tî ngûru", "Kɔ́bɛ tí ngúru"},
+	} {
+		actual, err := ConvertText(test.text, test.from, test.to)
+		if err != nil || actual != test.expected {
+			t.Errorf("ConvertText(%q, %v, %v) = %q, %v; expected %q", test.text, test.from, test.to, actual, err, test.expected)
+		}
+	}
+	if _, err := ConvertText("", []string{"klingon"}, nil); err == nil {
+		t.Errorf("ConvertText from an unknown profile: expected an error")
+	}
+	// The text would be output unchanged, without the tones it lacks.
+	if actual, err := ConvertText("Kobe ti nguru", []string{"toneless"}, nil); err == nil {
+		t.Errorf("ConvertText from toneless = %q, expected an error", actual)
+	}
+}
@@ -0,0 +1,50 @@
+package tts
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func Init(rootCmd *cobra.Command) {
+	ttsCmd.Flags().StringVar(&formatFlagValue, "format", "ssml", "Output format: ssml or json.")
+	ttsCmd.Flags().BoolVar(&xsampaFlagValue, "xsampa", false, "Writes phones in X-SAMPA instead of IPA.")
+	rootCmd.AddCommand(ttsCmd)
+}
+
+var (
+	formatFlagValue string
+	xsampaFlagValue bool
+
+	ttsCmd = &cobra.Command{
+		Use:   "tts",
+		Short: "Read Sango text from stdin, convert it to phones, tones, and phrase breaks for speech synthesis, then write to stdout",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if formatFlagValue != "ssml" && formatFlagValue != "json" {
+				log.Fatalf("Unknown --format %q", formatFlagValue)
+			}
+			b, err := io.ReadAll(bufio.NewReader(os.Stdin))
+			if err != nil {
+				log.Fatal(err)
+			}
+			options := Options{XSAMPA: xsampaFlagValue}
+			phrases, err := Analyze(string(b), options)
+			if err != nil {
+				log.Print(err)
+			}
+			switch formatFlagValue {
+			case "ssml":
+				err = WriteSSML(os.Stdout, phrases, options)
+			case "json":
+				err = WriteJSON(os.Stdout, phrases)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+)
@@ -0,0 +1,262 @@
+// Grapheme-to-phoneme front end for Sango text-to-speech
+//
+// Analyze turns Sango text into phrases of words, and each Sango word into
+// syllables with their phones and lexical tone. Numbers written in digits are
+// first spelled out as Sango number words. Punctuation ends a phrase with a
+// prosodic boundary: a minor break after , ; :, a major break after . and …,
+// a final rise after ?, and emphasis after !. Words that are not Sango are kept
+// as text, for the synthesizer to pronounce as it can.
+//
+// WriteSSML and WriteJSON then write the phrases for a speech synthesizer.
+
+package tts
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/zokwezo/sango/src/lib/numbers"
+	"github.com/zokwezo/sango/src/lib/sse"
+	"golang.org/x/text/unicode/norm"
+)
+
+type Options struct {
+	XSAMPA bool // phones in X-SAMPA, else in IPA
+}
+
+type Boundary string
+
+const (
+	BoundaryNone        Boundary = ""            // end of text without punctuation
+	BoundaryMinor       Boundary = "minor"       // , ; :
+	BoundaryMajor       Boundary = "major"       // . …
+	BoundaryQuestion    Boundary = "question"    // ?
+	BoundaryExclamation Boundary = "exclamation" // !
+)
+
+type Syllable struct {
+	Phones []string `json:"phones"`         // consonant (if any), then vowel
+	Tone   string   `json:"tone,omitempty"` // H, M, L, or empty if unknown
+}
+
+type Word struct {
+	Text      string     `json:"text"`
+	Syllables []Syllable `json:"syllables,omitempty"` // nil if the word is not Sango
+}
+
+type Phrase struct {
+	Words    []Word   `json:"words"`
+	Boundary Boundary `json:"boundary,omitempty"`
+}
+
+// Returns the phrases of Sango text, e.g. "Mbï yeke sô." -> one phrase of
+// three words with BoundaryMajor. The error reports text that could not be
+// encoded, which is skipped.
+func Analyze(text string, options Options) ([]Phrase, error) {
+	return analyze(text, options)
+}
+
+// Writes the phrases as an SSML 1.1 document, with the pronunciation of each
+// Sango word in a <phoneme> element and the boundaries as breaks and prosody.
+func WriteSSML(out io.Writer, phrases []Phrase, options Options) error {
+	return writeSSML(out, phrases, options)
+}
+
+// Writes the phrases as indented JSON.
+func WriteJSON(out io.Writer, phrases []Phrase) error {
+	return writeJSON(out, phrases)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+var digitsRE = regexp.MustCompile(`[0-9]+`)
+
+// Spells out each number in digits, or else each of its digits (e.g. if it is too large).
+func spellNumbers(text string) string {
+	return digitsRE.ReplaceAllStringFunc(numbers.Normalize(text), func(digits string) string {
+		var words []string
+		for _, d := range digits {
+			word, _ := numbers.Cardinal(int64(d - '0'))
+			words = append(words, word)
+		}
+		return " " + strings.Join(words, " ") + " "
+	})
+}
+
+var boundaries = map[rune]Boundary{
+	',': BoundaryMinor,
+	';': BoundaryMinor,
+	':': BoundaryMinor,
+	'.': BoundaryMajor,
+	'…': BoundaryMajor,
+	'?': BoundaryQuestion,
+	'!': BoundaryExclamation,
+}
+
+type analyzer struct {
+	phrases []Phrase
+	words   []Word // of the current phrase
+	open    bool   // whether the last word may continue
+}
+
+func analyze(text string, options Options) ([]Phrase, error) {
+	sses, err := sse.UTF8ToSSEs(spellNumbers(text))
+	a := analyzer{phrases: []Phrase{}}
+	for _, x := range sses {
+		var s strings.Builder
+		x.WriteUTF8To(&s, asText)
+		if syllables := x.PhoneticSyllables(options.XSAMPA); syllables != nil {
+			a.addSango(s.String(), syllables)
+		} else {
+			for _, r := range s.String() {
+				a.addRune(r)
+			}
+		}
+	}
+	a.endPhrase(BoundaryNone)
+	return a.phrases, err
+}
+
+// Sango words are written in lowercase, e.g. Kɔ̂bɛ -> kɔ̂bɛ.
+var asText = sse.WriteUTF8Options{ForSpaceUse: " ", ForHyphenUse: "-", WithShift: false, WithHeight: true, WithNTilde: true, WithPitch: true}
+
+func (a *analyzer) last() *Word {
+	return &a.words[len(a.words)-1]
+}
+
+// Adds the syllables of an SSE, which continue the last word unless they follow a space.
+func (a *analyzer) addSango(text string, syllables []sse.PhoneticSyllable) {
+	var ss []Syllable
+	for _, syllable := range syllables {
+		phones := []string{syllable.Vowel}
+		if syllable.Consonant != "" {
+			phones = []string{syllable.Consonant, syllable.Vowel}
+		}
+		ss = append(ss, Syllable{Phones: phones, Tone: syllable.Tone})
+	}
+	text, spaced := strings.CutPrefix(text, " ")
+	if !spaced && a.open && a.last().Syllables != nil {
+		a.last().Text += text
+		a.last().Syllables = append(a.last().Syllables, ss...)
+		return
+	}
+	a.words = append(a.words, Word{Text: text, Syllables: ss})
+	a.open = true
+}
+
+// Adds a rune that is not part of a Sango word: a letter of a foreign word,
+// punctuation that ends a phrase, or anything else, which ends a word.
+// Punctuation that does not follow a word (e.g. the rest of ...) is ignored.
+func (a *analyzer) addRune(r rune) {
+	boundary, isBoundary := boundaries[r]
+	switch {
+	case isBoundary:
+		a.endPhrase(boundary)
+	case unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r):
+		if a.open && a.last().Syllables == nil {
+			a.last().Text += string(r)
+		} else {
+			a.words = append(a.words, Word{Text: string(r)})
+			a.open = true
+		}
+	default:
+		a.open = false
+	}
+}
+
+func (a *analyzer) endPhrase(boundary Boundary) {
+	a.open = false
+	if len(a.words) > 0 {
+		a.phrases = append(a.phrases, Phrase{Words: a.words, Boundary: boundary})
+		a.words = nil
+	}
+}
+
+var ipaTones = map[string]string{"H": "\u0301", "M": "\u0304", "L": "\u0300"}
+var xsampaTones = map[string]string{"H": "_H", "M": "_M", "L": "_L"}
+
+// Returns the pronunciation of a Sango word, with tone marks and syllables separated by '.'.
+func pronunciation(word Word, options Options) string {
+	var ss []string
+	for _, syllable := range word.Syllables {
+		s := strings.Join(syllable.Phones, "")
+		if options.XSAMPA {
+			s += xsampaTones[syllable.Tone]
+		} else {
+			s = norm.NFC.String(s + ipaTones[syllable.Tone])
+		}
+		ss = append(ss, s)
+	}
+	return strings.Join(ss, ".")
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func writeSSML(out io.Writer, phrases []Phrase, options Options) error {
+	alphabet := "ipa"
+	if options.XSAMPA {
+		alphabet = "x-sampa"
+	}
+	w := bufio.NewWriter(out)
+	w.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	w.WriteString(`<speak version="1.1" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="sg">` + "\n")
+	inSentence := false
+	for _, phrase := range phrases {
+		if !inSentence {
+			w.WriteString("<s>\n")
+			inSentence = true
+		}
+		if phrase.Boundary == BoundaryExclamation {
+			w.WriteString(`<emphasis level="strong">` + "\n")
+		}
+		for k, word := range phrase.Words {
+			rise := phrase.Boundary == BoundaryQuestion && k == len(phrase.Words)-1
+			if rise {
+				w.WriteString(`<prosody pitch="high">`)
+			}
+			if word.Syllables == nil {
+				w.WriteString(escape(word.Text))
+			} else {
+				fmt.Fprintf(w, `<phoneme alphabet="%s" ph="%s">%s</phoneme>`,
+					alphabet, escape(pronunciation(word, options)), escape(word.Text))
+			}
+			if rise {
+				w.WriteString("</prosody>")
+			}
+			w.WriteString("\n")
+		}
+		if phrase.Boundary == BoundaryExclamation {
+			w.WriteString("</emphasis>\n")
+		}
+		switch phrase.Boundary {
+		case BoundaryMinor:
+			w.WriteString(`<break strength="medium"/>` + "\n")
+		case BoundaryMajor, BoundaryQuestion, BoundaryExclamation:
+			w.WriteString("</s>\n")
+			inSentence = false
+		}
+	}
+	if inSentence {
+		w.WriteString("</s>\n")
+	}
+	w.WriteString("</speak>\n")
+	return w.Flush()
+}
+
+func writeJSON(out io.Writer, phrases []Phrase) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(phrases)
+}
@@ -0,0 +1,112 @@
+package tts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	phrases, err := Analyze("Kɔ̂bɛ tî ngûru, 25 taxi? Ɛ̈!", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, phrase := range phrases {
+		var words []string
+		for _, word := range phrase.Words {
+			var syllables []string
+			for _, syllable := range word.Syllables {
+				syllables = append(syllables, strings.Join(syllable.Phones, " ")+" "+syllable.Tone)
+			}
+			words = append(words, word.Text+"="+strings.Join(syllables, "|"))
+		}
+		actual = append(actual, strings.Join(words, " ")+" "+string(phrase.Boundary))
+	}
+	expected := []string{
+		"kɔ̂bɛ=k ɔ H|b ɛ L tî=t i H ngûru=ᵑɡ u H|r u L minor",
+		"balë-ûse=b a L|l e M|u H|s e L na=n a L ɔkü=ɔ L|k u M taxi= question",
+		"ɛ̈=ɛ M exclamation",
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("bad Analyze\nexpected: %q\nactual:   %q", expected, actual)
+	}
+}
+
+func TestWriteSSML(t *testing.T) {
+	for _, test := range []struct {
+		options  Options
+		expected string
+	}{
+		{Options{}, `<?xml version="1.0" encoding="UTF-8"?>
+<speak version="1.1" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="sg">
+<s>
+<phoneme alphabet="ipa" ph="ᵐbī">mbï</phoneme>
+<break strength="medium"/>
+<phoneme alphabet="ipa" ph="lé.ᵑɡé">lêngê</phoneme>
+<prosody pitch="high">Tom</prosody>
+</s>
+</speak>
+`},
+		{Options{XSAMPA: true}, `<?xml version="1.0" encoding="UTF-8"?>
+<speak version="1.1" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="sg">
+<s>
+<phoneme alphabet="x-sampa" ph="mbi_M">mbï</phoneme>
+<break strength="medium"/>
+<phoneme alphabet="x-sampa" ph="le_H.Nge_H">lêngê</phoneme>
+<prosody pitch="high">Tom</prosody>
+</s>
+</speak>
+`},
+	} {
+		phrases, err := Analyze("Mbï: lêngê Tom?", test.options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual strings.Builder
+		if err := WriteSSML(&actual, phrases, test.options); err != nil {
+			t.Fatal(err)
+		}
+		if actual.String() != test.expected {
+			t.Errorf("bad WriteSSML(%+v)\nexpected: %v\nactual:   %v", test.options, test.expected, actual.String())
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	phrases, err := Analyze("Ala", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual strings.Builder
+	if err := WriteJSON(&actual, phrases); err != nil {
+		t.Fatal(err)
+	}
+	expected := `[
+  {
+    "words": [
+      {
+        "text": "ala",
+        "syllables": [
+          {
+            "phones": [
+              "a"
+            ],
+            "tone": "L"
+          },
+          {
+            "phones": [
+              "l",
+              "a"
+            ],
+            "tone": "L"
+          }
+        ]
+      }
+    ]
+  }
+]
+`
+	if actual.String() != expected {
+		t.Errorf("bad WriteJSON\nexpected: %v\nactual:   %v", expected, actual.String())
+	}
+}
@@ -4,11 +4,22 @@ import (
 	"log"
 
 	"github.com/spf13/cobra"
+	"github.com/zokwezo/sango/src/lib/align"
+	"github.com/zokwezo/sango/src/lib/corpus"
+	"github.com/zokwezo/sango/src/lib/gloss"
+	"github.com/zokwezo/sango/src/lib/hyphenate"
+	"github.com/zokwezo/sango/src/lib/ime"
 	"github.com/zokwezo/sango/src/lib/lexicon"
+	"github.com/zokwezo/sango/src/lib/numbers"
+	"github.com/zokwezo/sango/src/lib/render"
+	"github.com/zokwezo/sango/src/lib/repl"
 	"github.com/zokwezo/sango/src/lib/restore"
+	"github.com/zokwezo/sango/src/lib/serve"
+	"github.com/zokwezo/sango/src/lib/sse/features"
 	"github.com/zokwezo/sango/src/lib/tokenize"
 	"github.com/zokwezo/sango/src/lib/transcode"
 	"github.com/zokwezo/sango/src/lib/transliterate"
+	"github.com/zokwezo/sango/src/lib/tts"
 )
 
 var (
@@ -20,11 +31,22 @@ var (
 )
 
 func init() {
+	align.Init(sangoCmd)
+	corpus.Init(sangoCmd)
+	features.Init(sangoCmd)
+	gloss.Init(sangoCmd)
+	hyphenate.Init(sangoCmd)
+	ime.Init(sangoCmd)
 	lexicon.Init(sangoCmd)
+	numbers.Init(sangoCmd)
+	render.Init(sangoCmd)
+	repl.Init(sangoCmd)
 	restore.Init(sangoCmd)
+	serve.Init(sangoCmd)
 	tokenize.Init(sangoCmd)
 	transcode.Init(sangoCmd)
 	transliterate.Init(sangoCmd)
+	tts.Init(sangoCmd)
 }
 
 func main() {
@@ -0,0 +1,17 @@
+#!/bin/sh
+# Vendors the pinned htmx release into static/ (run by `go generate`), checking it
+# against its published SRI hash before it is embedded in the binary.
+set -eu
+cd "$(dirname "$0")/static"
+
+url=https://unpkg.com/htmx.org@2.0.2/dist/htmx.min.js
+sri=sha384-Y7hw+L/jvKeWIRRkqWYfPcvVxHzVzn5REgzbawhxAuQGwX1XWe70vji+VSeHOThJ
+
+curl -fsSL "$url" -o htmx.min.js.tmp
+actual="sha384-$(openssl dgst -sha384 -binary htmx.min.js.tmp | openssl base64 -A)"
+if [ "$actual" != "$sri" ]; then
+  echo "htmx.min.js: expected $sri, got $actual" >&2
+  rm htmx.min.js.tmp
+  exit 1
+fi
+mv htmx.min.js.tmp htmx.min.js
@@ -1,116 +1,207 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"embed"
+	"errors"
+	"flag"
 	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/zokwezo/sango/src/lib/corpus"
+	"github.com/zokwezo/sango/src/lib/lexicon"
 )
 
-var dbDvdRental *sql.DB
+var (
+	port     = flag.Int("port", 8000, "Port on which to serve the Sango dictionary")
+	dbSource = flag.String("db", "", "Postgres connection string, e.g. \"host=localhost dbname=sango sslmode=disable\"; if empty, serves from memory")
+	load     = flag.Bool("load", false, "Create the tables in schema.sql and (re)load them with the lexicon and -corpus")
+	corpora  = flag.String("corpus", "", "Comma-separated CoNLL-U files of example sentences")
+	timeout  = flag.Duration("timeout", 5*time.Second, "Maximum duration of each lookup")
+)
+
+// Serves the lexicon and corpus examples.
+var repository Repository
 
 func main() {
 	log.SetFlags(log.Lshortfile)
-	var err error
-	dbDvdRental, err = sql.Open(
-		"postgres",
-		"host=localhost dbname=dvdrental connect_timeout=5 statement_timeout=30 sslmode=disable",
-	)
-	if err != nil {
-		log.Fatal(err)
+	flag.Parse()
+
+	var sentences []corpus.Sentence
+	for _, path := range strings.Split(*corpora, ",") {
+		if path == "" {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s, err := corpus.Parse(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%v: %v", path, err)
+		}
+		sentences = append(sentences, s...)
 	}
 
-	http.HandleFunc("/", onLoadHandler)
-	http.HandleFunc("/fetch-actors/", fetchActorsHandler)
+	if *dbSource == "" {
+		repository = NewMemoryRepository(sentences)
+	} else {
+		db, err := sql.Open("postgres", *dbSource)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		if err := db.PingContext(ctx); err != nil {
+			log.Fatal(err)
+		}
+		if *load {
+			if err := LoadSQL(ctx, db, lexicon.LexiconRows(), sentences); err != nil {
+				log.Fatal(err)
+			}
+		}
+		cancel()
+		repository = NewSQLRepository(db)
+	}
 
-	fmt.Println("Navigate browser to http://localhost:8000")
-	log.Fatal(http.ListenAndServe(":8000", nil))
+	fmt.Printf("Navigate browser to http://localhost:%v\n", *port)
+	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%v", *port), newMux()))
 }
 
-type Actor struct {
-	FirstName string
-	LastName  string
+// The templates and static assets are compiled into the binary, so the server
+// runs from any directory and needs no network access beyond its own port.
+//
+//go:generate sh fetch_static.sh
+//go:embed translate.html entry.html static
+var files embed.FS
+
+var templates = template.Must(template.ParseFS(files, "translate.html", "entry.html"))
+
+func newMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", onLoadHandler)
+	mux.HandleFunc("GET /search", searchHandler)
+	mux.HandleFunc("GET /entry/{index}", entryHandler)
+	mux.Handle("GET /static/", http.FileServerFS(files))
+	return mux
+}
+
+// Maximum number of search results and corpus examples to display.
+const (
+	maxResults  = 100
+	maxExamples = 10
+)
+
+type Entry struct {
+	Index int // into lexicon.LexiconRows()
+	Row   lexicon.DictRow
+}
+
+type SearchPage struct {
+	Query     string
+	Lang      string // "sg" to look up Sango, "en" to look up English
+	Entries   []Entry
+	Truncated bool
+}
+
+type EntryPage struct {
+	Entry
+	Homographs []Entry
+	Examples   []Example
 }
-type Actors []Actor
-type ActorsMap map[string]Actors
 
 func onLoadHandler(w http.ResponseWriter, r *http.Request) {
-	tmpl := template.Must(template.ParseFiles("translate.html"))
-	actorsMap := ActorsMap{"Actors": {}}
-	err := tmpl.Execute(w, actorsMap)
+	page, err := search(r)
 	if err != nil {
-		log.Fatal(err)
+		serveError(w, err)
+		return
+	}
+	if err := templates.ExecuteTemplate(w, "translate.html", page); err != nil {
+		log.Print(err)
 	}
 }
 
-func fetchActorsHandler(w http.ResponseWriter, r *http.Request) {
-	tmpl := template.Must(template.ParseFiles("translate.html"))
-	time.Sleep(500 * time.Millisecond)
-	firstNameToMatch := r.PostFormValue("firstName")
-	lastNameToMatch := r.PostFormValue("lastName")
-	actorsMap := GetActorsMapFromDatabase(dbDvdRental, firstNameToMatch, lastNameToMatch)
-	err := tmpl.ExecuteTemplate(w, "actor-table-elements", actorsMap)
+func searchHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := search(r)
 	if err != nil {
-		log.Fatal(err)
+		serveError(w, err)
+		return
+	}
+	if err := templates.ExecuteTemplate(w, "search-results", page); err != nil {
+		log.Print(err)
 	}
 }
 
-func GetActorsMapFromDatabase(db *sql.DB, firstNameToMatch, lastNameToMatch string) ActorsMap {
-	rows, err := db.Query(makeQuerySql(firstNameToMatch, lastNameToMatch))
+func entryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), *timeout)
+	defer cancel()
+	index, err := strconv.Atoi(r.PathValue("index"))
 	if err != nil {
-		log.Fatal(err)
+		http.NotFound(w, r)
+		return
 	}
-	defer rows.Close()
-
-	actors := make(Actors, 0, 256)
-	for rows.Next() {
-		var actor Actor
-		err := rows.Scan(&actor.FirstName, &actor.LastName)
-		if err != nil {
-			log.Fatal(err)
-		}
-		actors = append(actors, actor)
+	entry, err := repository.Entry(ctx, index)
+	if err != nil {
+		serveError(w, err)
+		return
 	}
-	err = rows.Err()
+	page := EntryPage{Entry: entry}
+	homographs, err := repository.LookupSango(ctx, entry.Row.Lemma, maxResults)
 	if err != nil {
-		log.Fatal(err)
+		serveError(w, err)
+		return
+	}
+	for _, e := range homographs {
+		if e.Index != index {
+			page.Homographs = append(page.Homographs, e)
+		}
+	}
+	if page.Examples, err = repository.Examples(ctx, entry.Row.Lemma, maxExamples); err != nil {
+		serveError(w, err)
+		return
+	}
+	if err := templates.ExecuteTemplate(w, "entry.html", page); err != nil {
+		log.Print(err)
 	}
-
-	actorsMap := make(ActorsMap)
-	actorsMap["Actors"] = actors
-	return actorsMap
 }
 
-func makeQuerySql(firstNameToMatch, lastNameToMatch string) string {
-	makeWhereClause := func() string {
-		whereClause := ""
-		delim := " WHERE"
-		if firstNameToMatch != "" {
-			whereClause += delim +
-				" REGEXP_LIKE(first_name, '" +
-				strings.TrimSuffix(strings.TrimPrefix(strconv.Quote(firstNameToMatch), "\""), "\"") +
-				"')"
-			delim = " AND"
-		}
-		if lastNameToMatch != "" {
-			whereClause += delim +
-				" REGEXP_LIKE(last_name, '" +
-				strings.TrimSuffix(strings.TrimPrefix(strconv.Quote(lastNameToMatch), "\""), "\"") +
-				"')"
-			// delim = " AND"
-		}
-		return whereClause
+// Logs err and responds with an error page, leaving the server running.
+func serveError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errNotFound):
+		http.Error(w, "404 page not found", http.StatusNotFound)
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Print(err)
+		http.Error(w, "The dictionary is busy, please try again.", http.StatusServiceUnavailable)
+	default:
+		log.Print(err)
+		http.Error(w, "The dictionary is unavailable, please try again later.", http.StatusInternalServerError)
 	}
+}
 
-	querySql := "SELECT first_name, last_name FROM actor"
-	if whereClause := makeWhereClause(); whereClause != "" {
-		querySql += whereClause
+func search(r *http.Request) (SearchPage, error) {
+	ctx, cancel := context.WithTimeout(r.Context(), *timeout)
+	defer cancel()
+	page := SearchPage{Query: r.FormValue("q"), Lang: r.FormValue("lang")}
+	var err error
+	switch page.Lang {
+	case "en":
+		page.Entries, err = repository.LookupEnglish(ctx, page.Query, maxResults+1)
+	default:
+		page.Lang = "sg"
+		page.Entries, err = repository.LookupSango(ctx, page.Query, maxResults+1)
+	}
+	if len(page.Entries) > maxResults {
+		page.Entries = page.Entries[:maxResults]
+		page.Truncated = true
 	}
-	return querySql
+	return page, err
 }
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zokwezo/sango/src/lib/corpus"
+)
+
+func get(t *testing.T, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	newMux().ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+	return w
+}
+
+const fixture = "# sent id = tere-p1s1\n# text = Lo te kɔ̂bɛ.\n# text_en = He eats food.\n" +
+	"1\tLo\tlo\tPRON\t_\t_\t_\t_\t_\t_\n2\tte\tte\tVERB\t_\t_\t_\t_\t_\t_\n3\tkɔ̂bɛ\tkɔ̂bɛ\tNOUN\t_\t_\t_\t_\t_\t_\n4\t.\t.\tPUNCT\t_\t_\t_\t_\t_\t_\n\n"
+
+func fixtureSentences(t *testing.T) []corpus.Sentence {
+	t.Helper()
+	sentences, err := corpus.Parse(strings.NewReader(fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sentences
+}
+
+// Checks what every Repository of the lexicon and the fixture must do, whether
+// in memory or in a database.
+func testRepositoryContract(t *testing.T, r Repository) {
+	ctx := context.Background()
+	for _, word := range []string{"kobe", "kɔ̂bɛ", " KOBE "} {
+		if entries, err := r.LookupSango(ctx, word, 3); err != nil || len(entries) == 0 || len(entries) > 3 || entries[0].Row.Lemma != "kɔ̂bɛ" {
+			t.Errorf("LookupSango(%q) = %+v, %v", word, entries, err)
+		}
+	}
+	if entries, err := r.LookupSango(ctx, "ngbang", 5); err != nil || len(entries) == 0 {
+		t.Errorf("LookupSango(ngbang) = %+v, %v", entries, err)
+	} else {
+		for _, e := range entries {
+			if !strings.HasPrefix(e.Row.Toneless, "ngbang") {
+				t.Errorf("LookupSango(ngbang): %+v", e)
+			}
+		}
+	}
+	if entries, err := r.LookupSango(ctx, " ", 5); err != nil || len(entries) != 0 {
+		t.Errorf("LookupSango(\" \") = %+v, %v", entries, err)
+	}
+	if entries, err := r.LookupEnglish(ctx, "food", 5); err != nil || len(entries) == 0 || entries[0].Row.EnglishTranslation != "food" {
+		t.Errorf("LookupEnglish(food) = %+v, %v", entries, err)
+	}
+	for _, word := range []string{"f.od", "[a-z]+", "food|water"} { // not patterns
+		if entries, err := r.LookupEnglish(ctx, word, 5); err != nil || len(entries) != 0 {
+			t.Errorf("LookupEnglish(%q) = %+v, %v", word, entries, err)
+		}
+	}
+
+	entries, err := r.LookupSango(ctx, "kobe", 1)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("LookupSango(kobe) = %+v, %v", entries, err)
+	}
+	if e, err := r.Entry(ctx, entries[0].Index); err != nil || e != entries[0] {
+		t.Errorf("Entry(%v) = %+v, %v", entries[0].Index, e, err)
+	}
+	for _, index := range []int{-1, 0, 1_000_000} {
+		if e, err := r.Entry(ctx, index); err != errNotFound {
+			t.Errorf("Entry(%v) = %+v, %v", index, e, err)
+		}
+	}
+	if examples, err := r.Examples(ctx, "kobe", 5); err != nil || len(examples) != 1 ||
+		examples[0] != (Example{"tere-p1s1", "Lo te kɔ̂bɛ.", "He eats food."}) {
+		t.Errorf("Examples(kobe) = %+v, %v", examples, err)
+	}
+	if examples, err := r.Examples(ctx, "ngbangbo", 5); err != nil || len(examples) != 0 {
+		t.Errorf("Examples(ngbangbo) = %+v, %v", examples, err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := r.LookupSango(canceled, "kobe", 1); err == nil {
+		t.Error("LookupSango: expected an error once canceled")
+	}
+	if _, err := r.LookupEnglish(canceled, "food", 1); err == nil {
+		t.Error("LookupEnglish: expected an error once canceled")
+	}
+	if _, err := r.Entry(canceled, entries[0].Index); err == nil {
+		t.Error("Entry: expected an error once canceled")
+	}
+	if _, err := r.Examples(canceled, "kobe", 1); err == nil {
+		t.Error("Examples: expected an error once canceled")
+	}
+}
+
+func TestMemoryRepository(t *testing.T) {
+	repository = NewMemoryRepository(fixtureSentences(t))
+	testRepositoryContract(t, repository)
+
+	w := get(t, "/search?q=kobe&lang=sg")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "kɔ̂bɛ") {
+		t.Fatalf("search: %v %s", w.Code, w.Body.String())
+	}
+	entries, err := repository.LookupSango(context.Background(), "kobe", 1)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("LookupSango = %v, %v", entries, err)
+	}
+	w = get(t, "/entry/"+strconv.Itoa(entries[0].Index))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "He eats food.") {
+		t.Errorf("entry: %v %s", w.Code, w.Body.String())
+	}
+	if w := get(t, "/entry/0"); w.Code != http.StatusNotFound {
+		t.Errorf("entry 0: %v", w.Code)
+	}
+}
+
+func TestExamplesOfCorpus(t *testing.T) {
+	sentences, err := corpus.ReadFiles([]string{"../../corpora/les_ruses_de_tere/tere_na_nguru.conllu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	examples, err := NewMemoryRepository(sentences).Examples(context.Background(), "lo", maxExamples)
+	if err != nil || len(examples) == 0 {
+		t.Fatalf("Examples = %v, %v", examples, err)
+	}
+	for _, example := range examples {
+		if !strings.HasPrefix(example.SentID, "tere_na_nguru-") || example.Text == "" {
+			t.Errorf("example = %+v", example)
+		}
+	}
+}
+
+// Fails every lookup, as a database that is down or too slow would.
+type failingRepository struct {
+	err error
+}
+
+func (f failingRepository) LookupSango(context.Context, string, int) ([]Entry, error) {
+	return nil, f.err
+}
+func (f failingRepository) LookupEnglish(context.Context, string, int) ([]Entry, error) {
+	return nil, f.err
+}
+func (f failingRepository) Entry(context.Context, int) (Entry, error) { return Entry{}, f.err }
+func (f failingRepository) Examples(context.Context, string, int) ([]Example, error) {
+	return nil, f.err
+}
+
+func TestRepositoryErrors(t *testing.T) {
+	for _, test := range []struct {
+		err    error
+		status int
+	}{
+		{context.DeadlineExceeded, http.StatusServiceUnavailable},
+		{errors.New("connection refused"), http.StatusInternalServerError},
+		{errNotFound, http.StatusNotFound},
+	} {
+		repository = failingRepository{test.err}
+		for _, target := range []string{"/search?q=kobe", "/search?q=pig&lang=en", "/entry/1"} {
+			if w := get(t, target); w.Code != test.status {
+				t.Errorf("%v with %v: %v, expected %v", target, test.err, w.Code, test.status)
+			}
+		}
+	}
+}
+
+var staticRE = regexp.MustCompile(`(?:src|href)="(/static/[^"]+)"`)
+
+func TestEmbeddedFiles(t *testing.T) {
+	repository = NewMemoryRepository(nil)
+	var assets []string
+	for _, target := range []string{"/", "/entry/1"} {
+		w := get(t, target)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%v: %v %s", target, w.Code, w.Body.String())
+		}
+		for _, m := range staticRE.FindAllStringSubmatch(w.Body.String(), -1) {
+			assets = append(assets, m[1])
+		}
+	}
+	for _, asset := range []string{"/static/style.css", "/static/htmx.min.js"} {
+		if !slices.Contains(assets, asset) {
+			t.Errorf("%v is not referenced by the pages: %v", asset, assets)
+		}
+	}
+	// Every asset referenced by the pages is embedded, so none is fetched from elsewhere.
+	for _, asset := range append(assets, "/static/README.md") {
+		if w := get(t, asset); w.Code != http.StatusOK || w.Body.Len() == 0 {
+			t.Errorf("%v: %v with %v bytes", asset, w.Code, w.Body.Len())
+		}
+	}
+	if w := get(t, "/static/../main.go"); w.Code == http.StatusOK {
+		t.Errorf("/static/../main.go: %v", w.Code)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zokwezo/sango/src/lib/corpus"
+	"github.com/zokwezo/sango/src/lib/lexicon"
+)
+
+// Returns a Repository of the lexicon and corpus tables in schema.sql, in Postgres.
+// Every query is parameterized, so user input is never interpolated into SQL.
+func NewSQLRepository(db *sql.DB) Repository {
+	return &sqlRepository{db, postgresDialect}
+}
+
+// Creates any missing tables, then replaces their contents in one transaction.
+func LoadSQL(ctx context.Context, db *sql.DB, rows lexicon.DictRows, sentences []corpus.Sentence) error {
+	return loadSQL(ctx, db, postgresDialect, rows, sentences)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+//go:embed schema.sql
+var schemaSQL string
+
+const lexiconColumns = `id, toneless, heightless, lemma, canonical, ud_pos, ud_feature, category,
+	frequency, english_translation, english_definition`
+
+// The SQL of one database engine, so that sqlRepository itself is portable.
+// Each query binds its arguments as parameters, in the order given.
+type sqlDialect struct {
+	schema string // creates any missing tables
+
+	// Lexicon rows, at most $2 of them: those of lexicon.TonelessKey $1, else those
+	// whose toneless spelling starts with $1, else those within an edit distance of $3.
+	lookupExact, lookupPrefix, lookupApprox string
+
+	// Lexicon rows by English word ($1), at most $4 of them, ranked by whether
+	// it is the translation ($1), is a word of the translation (pattern $2), or
+	// is a word of the definition (pattern $3).
+	lookupEnglish                         string
+	translationPattern, definitionPattern func(quoted string) string // of a regexp.QuoteMeta'd word
+
+	entry    string // the lexicon row of id $1
+	examples string // the sentences with a word of lexicon.TonelessKey $1, at most $2 of them
+
+	truncate                              string
+	insertRow, insertSentence, insertWord string // insertSentence returns the id of the sentence
+}
+
+var postgresDialect = sqlDialect{
+	schema: schemaSQL,
+
+	lookupExact: `SELECT ` + lexiconColumns + ` FROM lexicon WHERE toneless_key = $1 AND toneless <> ''
+		ORDER BY frequency, id LIMIT $2`,
+	lookupPrefix: `SELECT ` + lexiconColumns + ` FROM lexicon WHERE starts_with(toneless, $1)
+		ORDER BY frequency, id LIMIT $2`,
+	// levenshtein() is in the fuzzystrmatch extension.
+	lookupApprox: `SELECT ` + lexiconColumns + ` FROM lexicon WHERE levenshtein(toneless, $1) BETWEEN 1 AND $3
+		ORDER BY levenshtein(toneless, $1), frequency, id LIMIT $2`,
+
+	lookupEnglish: `SELECT ` + lexiconColumns + ` FROM lexicon
+		WHERE toneless <> '' AND (english_translation ~* $2 OR english_definition ~* $3)
+		ORDER BY CASE
+			WHEN lower(english_translation) = lower($1) THEN 0
+			WHEN english_translation ~* $2 THEN 1
+			ELSE 2
+		END, frequency, id LIMIT $4`,
+	translationPattern: func(quoted string) string { return `(^|[^[:alpha:]])` + quoted + `($|[^[:alpha:]])` },
+	definitionPattern:  func(quoted string) string { return `\m` + quoted + `\M` }, // word boundaries
+
+	entry: `SELECT ` + lexiconColumns + ` FROM lexicon WHERE id = $1 AND toneless <> ''`,
+	examples: `SELECT s.sent_id, s.text, s.text_en FROM sentence s
+		WHERE EXISTS (SELECT 1 FROM word w WHERE w.sentence_id = s.id AND w.toneless_key = $1)
+		ORDER BY s.id LIMIT $2`,
+
+	truncate: `TRUNCATE lexicon, sentence, word`,
+	insertRow: `INSERT INTO lexicon (` + lexiconColumns + `, toneless_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+	insertSentence: `INSERT INTO sentence (sent_id, text, text_en) VALUES ($1, $2, $3) RETURNING id`,
+	insertWord: `INSERT INTO word (sentence_id, position, form, lemma, upos, feats, toneless_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+}
+
+type sqlRepository struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+func (s *sqlRepository) LookupSango(ctx context.Context, word string, limit int) ([]Entry, error) {
+	word = strings.TrimSpace(word)
+	toneless := lexicon.TonelessOf(word)
+	if toneless == "" {
+		return nil, nil
+	}
+	queries := []struct {
+		sql  string
+		args []any
+	}{
+		{s.dialect.lookupExact, []any{lexicon.TonelessKey(word), limit}},
+		{s.dialect.lookupPrefix, []any{toneless, limit}},
+		{s.dialect.lookupApprox, []any{toneless, limit, 1 + utf8.RuneCountInString(toneless)/5}},
+	}
+	for _, q := range queries {
+		entries, err := s.queryEntries(ctx, q.sql, q.args...)
+		if err != nil || len(entries) > 0 {
+			return entries, err
+		}
+	}
+	return nil, nil
+}
+
+func (s *sqlRepository) LookupEnglish(ctx context.Context, word string, limit int) ([]Entry, error) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil, nil
+	}
+	// The patterns are bound as parameters, with their metacharacters quoted.
+	quoted := regexp.QuoteMeta(strings.ToLower(word))
+	return s.queryEntries(ctx, s.dialect.lookupEnglish,
+		word, s.dialect.translationPattern(quoted), s.dialect.definitionPattern(quoted), limit)
+}
+
+func (s *sqlRepository) Entry(ctx context.Context, index int) (Entry, error) {
+	entries, err := s.queryEntries(ctx, s.dialect.entry, index)
+	if err != nil {
+		return Entry{}, err
+	}
+	if len(entries) == 0 {
+		return Entry{}, errNotFound
+	}
+	return entries[0], nil
+}
+
+func (s *sqlRepository) Examples(ctx context.Context, word string, limit int) ([]Example, error) {
+	rows, err := s.db.QueryContext(ctx, s.dialect.examples, lexicon.TonelessKey(word), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var examples []Example
+	for rows.Next() {
+		var e Example
+		if err := rows.Scan(&e.SentID, &e.Text, &e.TextEn); err != nil {
+			return nil, err
+		}
+		examples = append(examples, e)
+	}
+	return examples, rows.Err()
+}
+
+func (s *sqlRepository) queryEntries(ctx context.Context, query string, args ...any) ([]Entry, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		r := &e.Row
+		if err := rows.Scan(&e.Index, &r.Toneless, &r.Heightless, &r.Lemma, &r.Canonical, &r.UDPos,
+			&r.UDFeature, &r.Category, &r.Frequency, &r.EnglishTranslation, &r.EnglishDefinition); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func loadSQL(ctx context.Context, db *sql.DB, dialect sqlDialect, rows lexicon.DictRows, sentences []corpus.Sentence) (err error) {
+	if _, err := db.ExecContext(ctx, dialect.schema); err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback())
+		}
+	}()
+	if _, err := tx.ExecContext(ctx, dialect.truncate); err != nil {
+		return err
+	}
+	insertRow, err := tx.PrepareContext(ctx, dialect.insertRow)
+	if err != nil {
+		return err
+	}
+	defer insertRow.Close()
+	for k, r := range rows {
+		if _, err := insertRow.ExecContext(ctx, k, r.Toneless, r.Heightless, r.Lemma, r.Canonical, r.UDPos,
+			r.UDFeature, r.Category, r.Frequency, r.EnglishTranslation, r.EnglishDefinition, r.TonelessKey()); err != nil {
+			return err
+		}
+	}
+	insertWord, err := tx.PrepareContext(ctx, dialect.insertWord)
+	if err != nil {
+		return err
+	}
+	defer insertWord.Close()
+	for k := range sentences {
+		s := &sentences[k]
+		var id int
+		if err := tx.QueryRowContext(ctx, dialect.insertSentence, s.Comment("sent id"), s.Comment("text"), s.Comment("text_en")).Scan(&id); err != nil {
+			return err
+		}
+		for n, w := range s.Words() {
+			if _, err := insertWord.ExecContext(ctx, id, n, w.Form, w.Lemma, w.UPOS, w.Feats, lexicon.TonelessKey(w.Form)); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
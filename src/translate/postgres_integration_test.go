@@ -0,0 +1,36 @@
+//go:build postgres
+
+// Runs the Repository contract against a live Postgres, e.g.
+//
+//	createdb sango_test
+//	TRANSLATE_TEST_DB="host=localhost dbname=sango_test sslmode=disable" go test -tags postgres .
+//
+// The tables of the database are replaced.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/zokwezo/sango/src/lib/lexicon"
+)
+
+func TestPostgresRepository(t *testing.T) {
+	source := os.Getenv("TRANSLATE_TEST_DB")
+	if source == "" {
+		t.Skip("TRANSLATE_TEST_DB is not set")
+	}
+	db, err := sql.Open("postgres", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := LoadSQL(context.Background(), db, lexicon.LexiconRows(), fixtureSentences(t)); err != nil {
+		t.Fatal(err)
+	}
+	testRepositoryContract(t, NewSQLRepository(db))
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zokwezo/sango/src/lib/lexicon"
+)
+
+// Returns a sqlRepository of Postgres whose queries must be exactly as expected.
+func newMockRepository(t *testing.T) (*sqlRepository, *sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+	return &sqlRepository{db, postgresDialect}, db, mock
+}
+
+var mockColumns = []string{"id", "toneless", "heightless", "lemma", "canonical", "ud_pos", "ud_feature",
+	"category", "frequency", "english_translation", "english_definition"}
+
+var kobe = Entry{Index: 42, Row: lexicon.DictRow{Toneless: "kobe", Heightless: "kɔ̂bɛ", Lemma: "kɔ̂bɛ",
+	Canonical: "kc^bx_", UDPos: "NOUN", Category: "FOOD", Frequency: 1, EnglishTranslation: "food",
+	EnglishDefinition: "food, meal"}}
+
+func mockRows(entries ...Entry) *sqlmock.Rows {
+	rows := sqlmock.NewRows(mockColumns)
+	for _, e := range entries {
+		r := e.Row
+		rows.AddRow(e.Index, r.Toneless, r.Heightless, r.Lemma, r.Canonical, r.UDPos, r.UDFeature,
+			r.Category, r.Frequency, r.EnglishTranslation, r.EnglishDefinition)
+	}
+	return rows
+}
+
+func TestSQLLookupSango(t *testing.T) {
+	r, _, mock := newMockRepository(t)
+	// Falls back from the same toneless form to a prefix, then to an edit distance.
+	mock.ExpectQuery(postgresDialect.lookupExact).WithArgs(lexicon.TonelessKey("kɔ̂bɛɛ"), 5).WillReturnRows(mockRows())
+	mock.ExpectQuery(postgresDialect.lookupPrefix).WithArgs("kobee", 5).WillReturnRows(mockRows())
+	mock.ExpectQuery(postgresDialect.lookupApprox).WithArgs("kobee", 5, 2).WillReturnRows(mockRows(kobe))
+	if entries, err := r.LookupSango(context.Background(), " Kɔ̂bɛɛ ", 5); err != nil || len(entries) != 1 || entries[0] != kobe {
+		t.Errorf("LookupSango = %+v, %v", entries, err)
+	}
+
+	// Stops at the first query with results, or an error.
+	mock.ExpectQuery(postgresDialect.lookupExact).WithArgs(lexicon.TonelessKey("kobe"), 1).WillReturnRows(mockRows(kobe))
+	if entries, err := r.LookupSango(context.Background(), "kobe", 1); err != nil || len(entries) != 1 {
+		t.Errorf("LookupSango = %+v, %v", entries, err)
+	}
+	mock.ExpectQuery(postgresDialect.lookupExact).WithArgs(lexicon.TonelessKey("kobe"), 1).WillReturnError(context.DeadlineExceeded)
+	if _, err := r.LookupSango(context.Background(), "kobe", 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("LookupSango: %v, expected %v", err, context.DeadlineExceeded)
+	}
+
+	// Makes no query for a word without letters.
+	if entries, err := r.LookupSango(context.Background(), " ", 1); err != nil || entries != nil {
+		t.Errorf("LookupSango = %+v, %v", entries, err)
+	}
+}
+
+func TestSQLLookupEnglish(t *testing.T) {
+	r, _, mock := newMockRepository(t)
+	// The word is bound as a parameter, with its metacharacters quoted in the patterns.
+	mock.ExpectQuery(postgresDialect.lookupEnglish).
+		WithArgs("C++", `(^|[^[:alpha:]])c\+\+($|[^[:alpha:]])`, `\mc\+\+\M`, 10).
+		WillReturnRows(mockRows())
+	if entries, err := r.LookupEnglish(context.Background(), " C++ ", 10); err != nil || len(entries) != 0 {
+		t.Errorf("LookupEnglish = %+v, %v", entries, err)
+	}
+	mock.ExpectQuery(postgresDialect.lookupEnglish).
+		WithArgs("food", `(^|[^[:alpha:]])food($|[^[:alpha:]])`, `\mfood\M`, 10).
+		WillReturnRows(mockRows(kobe))
+	if entries, err := r.LookupEnglish(context.Background(), "food", 10); err != nil || len(entries) != 1 || entries[0] != kobe {
+		t.Errorf("LookupEnglish = %+v, %v", entries, err)
+	}
+}
+
+func TestSQLEntry(t *testing.T) {
+	r, _, mock := newMockRepository(t)
+	mock.ExpectQuery(postgresDialect.entry).WithArgs(42).WillReturnRows(mockRows(kobe))
+	if e, err := r.Entry(context.Background(), 42); err != nil || e != kobe {
+		t.Errorf("Entry(42) = %+v, %v", e, err)
+	}
+	mock.ExpectQuery(postgresDialect.entry).WithArgs(0).WillReturnRows(mockRows())
+	if _, err := r.Entry(context.Background(), 0); err != errNotFound {
+		t.Errorf("Entry(0): %v, expected %v", err, errNotFound)
+	}
+	// A row that does not scan is an error, not a partial entry.
+	mock.ExpectQuery(postgresDialect.entry).WithArgs(7).
+		WillReturnRows(sqlmock.NewRows(mockColumns).AddRow(7, "", "", "", "", "", "", "", "often", "", ""))
+	if _, err := r.Entry(context.Background(), 7); err == nil {
+		t.Error("Entry(7): expected a scan error")
+	}
+}
+
+func TestSQLExamples(t *testing.T) {
+	r, _, mock := newMockRepository(t)
+	// Matches the words by lexicon.TonelessKey, however they are written.
+	mock.ExpectQuery(postgresDialect.examples).WithArgs(lexicon.TonelessKey("kobe"), 10).WillReturnRows(
+		sqlmock.NewRows([]string{"sent_id", "text", "text_en"}).AddRow("tere-p1s1", "Lo te kɔ̂bɛ.", "He eats food."))
+	examples, err := r.Examples(context.Background(), "kɔ̂bɛ", 10)
+	if err != nil || len(examples) != 1 || examples[0] != (Example{"tere-p1s1", "Lo te kɔ̂bɛ.", "He eats food."}) {
+		t.Errorf("Examples = %+v, %v", examples, err)
+	}
+}
+
+func TestLoadSQL(t *testing.T) {
+	_, db, mock := newMockRepository(t)
+	sentences := fixtureSentences(t)
+	rows := lexicon.DictRows{{}, kobe.Row}
+
+	mock.ExpectExec(postgresDialect.schema).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectExec(postgresDialect.truncate).WillReturnResult(sqlmock.NewResult(0, 0))
+	insertRow := mock.ExpectPrepare(postgresDialect.insertRow)
+	insertRow.ExpectExec().WithArgs(0, "", "", "", "", "", "", "", 0, "", "", "").WillReturnResult(sqlmock.NewResult(0, 1))
+	r := kobe.Row
+	insertRow.ExpectExec().WithArgs(1, r.Toneless, r.Heightless, r.Lemma, r.Canonical, r.UDPos, r.UDFeature,
+		r.Category, r.Frequency, r.EnglishTranslation, r.EnglishDefinition, lexicon.TonelessKey("kobe")).WillReturnResult(sqlmock.NewResult(0, 1))
+	insertWord := mock.ExpectPrepare(postgresDialect.insertWord)
+	mock.ExpectQuery(postgresDialect.insertSentence).WithArgs("tere-p1s1", "Lo te kɔ̂bɛ.", "He eats food.").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	for n, w := range [][]any{{"Lo", "lo", "PRON", "lo"}, {"te", "te", "VERB", "te"}, {"kɔ̂bɛ", "kɔ̂bɛ", "NOUN", "kobe"}, {".", ".", "PUNCT", "."}} {
+		insertWord.ExpectExec().WithArgs(3, n, w[0], w[1], w[2], "", lexicon.TonelessKey(w[3].(string))).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+	if err := loadSQL(context.Background(), db, postgresDialect, rows, sentences); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed insert rolls back the whole load.
+	failure := errors.New("disk full")
+	mock.ExpectExec(postgresDialect.schema).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectExec(postgresDialect.truncate).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare(postgresDialect.insertRow).ExpectExec().WillReturnError(failure)
+	mock.ExpectRollback()
+	if err := loadSQL(context.Background(), db, postgresDialect, rows, sentences); !errors.Is(err, failure) {
+		t.Errorf("loadSQL: %v, expected %v", err, failure)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/zokwezo/sango/src/lib/corpus"
+	"github.com/zokwezo/sango/src/lib/lexicon"
+)
+
+// Looks up lexicon entries and corpus examples, either in memory or in a SQL database.
+//
+// Each method returns at most limit results, and fails once ctx is done.
+type Repository interface {
+	LookupSango(ctx context.Context, word string, limit int) ([]Entry, error)
+	LookupEnglish(ctx context.Context, word string, limit int) ([]Entry, error)
+	Entry(ctx context.Context, index int) (Entry, error)                     // errNotFound if there is no such entry
+	Examples(ctx context.Context, word string, limit int) ([]Example, error) // of the same lexicon.TonelessKey
+}
+
+// A corpus sentence containing a word.
+type Example struct {
+	SentID string
+	Text   string
+	TextEn string
+}
+
+var errNotFound = errors.New("not found")
+
+// Returns a Repository of the compiled-in lexicon and the given corpus sentences.
+func NewMemoryRepository(sentences []corpus.Sentence) Repository {
+	return &memoryRepository{lexicon.LexiconRows(), sentences}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// IMPLEMENTATION
+
+type memoryRepository struct {
+	rows      lexicon.DictRows
+	sentences []corpus.Sentence
+}
+
+func (m *memoryRepository) LookupSango(ctx context.Context, word string, limit int) ([]Entry, error) {
+	return m.entries(ctx, lexicon.LookupSango(m.rows, word), limit)
+}
+
+func (m *memoryRepository) LookupEnglish(ctx context.Context, word string, limit int) ([]Entry, error) {
+	return m.entries(ctx, lexicon.LookupEnglish(m.rows, word), limit)
+}
+
+func (m *memoryRepository) Entry(ctx context.Context, index int) (Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return Entry{}, err
+	}
+	if index <= 0 || index >= len(m.rows) {
+		return Entry{}, errNotFound
+	}
+	return Entry{index, m.rows[index]}, nil
+}
+
+func (m *memoryRepository) Examples(ctx context.Context, word string, limit int) ([]Example, error) {
+	key := lexicon.TonelessKey(word)
+	var examples []Example
+	for k := range m.sentences {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(examples) >= limit {
+			break
+		}
+		s := &m.sentences[k]
+		if slices.ContainsFunc(s.Words(), func(w corpus.Token) bool { return lexicon.TonelessKey(w.Form) == key }) {
+			examples = append(examples, Example{s.Comment("sent id"), s.Comment("text"), s.Comment("text_en")})
+		}
+	}
+	return examples, nil
+}
+
+func (m *memoryRepository) entries(ctx context.Context, rows lexicon.DictRows, limit int) ([]Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	var entries []Entry
+	for _, row := range rows {
+		entries = append(entries, Entry{lexicon.IndexFromRow()[row], row})
+	}
+	return entries, nil
+}
@@ -0,0 +1,40 @@
+-- Postgres schema for the Sango lexicon and corpus (see postgres.go).
+-- Applied by `translate -db ... -load`, which then reloads every table.
+
+CREATE EXTENSION IF NOT EXISTS fuzzystrmatch; -- levenshtein()
+
+CREATE TABLE IF NOT EXISTS lexicon (
+  id                  integer PRIMARY KEY, -- index into lexicon.LexiconRows()
+  toneless            text NOT NULL,
+  heightless          text NOT NULL,
+  lemma               text NOT NULL,
+  canonical           text NOT NULL,
+  ud_pos              text NOT NULL,
+  ud_feature          text NOT NULL,
+  category            text NOT NULL,
+  frequency           integer NOT NULL,
+  english_translation text NOT NULL,
+  english_definition  text NOT NULL,
+  toneless_key        text NOT NULL  -- lexicon.TonelessKey of the row
+);
+CREATE INDEX IF NOT EXISTS lexicon_toneless ON lexicon (toneless text_pattern_ops);
+CREATE INDEX IF NOT EXISTS lexicon_toneless_key ON lexicon (toneless_key);
+
+CREATE TABLE IF NOT EXISTS sentence (
+  id      serial PRIMARY KEY,
+  sent_id text NOT NULL,
+  text    text NOT NULL,
+  text_en text NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS word (
+  sentence_id  integer NOT NULL REFERENCES sentence (id) ON DELETE CASCADE,
+  position     integer NOT NULL, -- index into Sentence.Words()
+  form         text NOT NULL,
+  lemma        text NOT NULL,
+  upos         text NOT NULL,
+  feats        text NOT NULL,
+  toneless_key text NOT NULL,    -- lexicon.TonelessKey of the form
+  PRIMARY KEY (sentence_id, position)
+);
+CREATE INDEX IF NOT EXISTS word_toneless_key ON word (toneless_key);